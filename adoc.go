@@ -0,0 +1,160 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// adocDump renders the same content as mdDump in AsciiDoc, for Antora and
+// Asciidoctor
+func adocDump(schema *Schema, w io.Writer, options MarkdownOptions) error {
+	tpl, err := template.New("schema.adoc").Funcs(template.FuncMap{
+		"adocescape": func(val string) string {
+			val = strings.ReplaceAll(val, "|", "\\|")
+			val = strings.ReplaceAll(val, "\n", " ")
+			return val
+		},
+		"join":         strings.Join,
+		"linkable":     options.linkable,
+		"anchor":       anchor,
+		"snakeToTitle": snakeToTitle,
+	}).Parse(adocTemplate)
+	if err != nil {
+		return err
+	}
+
+	data := execData{
+		Data: schema,
+	}
+	if options.NamingConventions != nil {
+		data.Conventions = analyseConventions(schema, options.NamingConventions)
+	}
+	if options.TopHubs > 0 {
+		data.Hubs = topHubs(schema, options.TopHubs)
+	}
+
+	return tpl.Execute(w, data)
+}
+
+var adocTemplate = `
+{{- if .Hubs }}
+== Most Referenced Tables
+
+|===
+| Table | Referenced by | References
+
+{{ range .Hubs -}}
+| <<{{ anchor .Name }},{{ .Name }}>> | {{ .FanIn }} | {{ .FanOut }}
+{{ end -}}
+|===
+
+{{ end -}}
+== Tables
+{{ range .Data.Tables }}
+[[{{ anchor .Name }}]]
+=== {{ snakeToTitle .Name }}
+{{ if or .FanIn .FanOut }}
+Referenced by {{ .FanIn }}, references {{ .FanOut }}
+{{ end }}
+{{ .Description }}
+
+[cols="1,1,3"]
+|===
+| Name | Type | Description
+
+{{ range .KeyColumns -}}
+| {{ .Name }} (KEY) | {{ if linkable . }}<<{{ anchor .ElementType }},{{ .DataType }}>>{{ else }}{{ .DataType }}{{ end }} | {{ adocescape .Description }}
+{{ end -}}
+{{ range .Columns -}}
+| {{ .Name }}{{ if .IsKey }} (KEY){{ end }}{{ if .Generated }} (GENERATED){{ end }} | {{ if linkable . }}<<{{ anchor .ElementType }},{{ .DataType }}>>{{ else }}{{ .DataType }}{{ end }} | {{ adocescape .Description }}
+{{ end -}}
+|===
+{{ range .ForeignKeys }}
+* {{ .Name }}{{ if .Logical }} (logical){{ end }}: {{ .ColumnList }} -> <<{{ anchor .RefTable }},{{ .RefTable }}>>.{{ .RefColumnList }}
+{{- end }}
+{{- range .Polymorphic }}
+* {{ .Name }} (polymorphic on {{ .TypeColumn }}, {{ .IDColumn }}{{ if .Targets }}: {{ join .Targets ", " }}{{ end }})
+{{- end }}
+{{- if .RLSEnabled }}
+
+==== Security Policies
+
+Row level security is enabled{{ if .RLSForced }} and forced for the table owner{{ end }}.
+{{ if .DeniesAll }}
+WARNING: There are no policies, so every row is hidden.
+{{ else }}
+|===
+| Policy | Command | Roles | Type | Using | With Check
+
+{{ range .Policies -}}
+| {{ .Name }} | {{ .Command }} | {{ join .Roles ", " }} | {{ if .Permissive }}permissive{{ else }}restrictive{{ end }} | {{ if .Using }}` + "`{{ adocescape .Using }}`" + `{{ end }} | {{ if .WithCheck }}` + "`{{ adocescape .WithCheck }}`" + `{{ end }}
+{{ end -}}
+|===
+{{- end }}
+{{- end }}
+{{- if .HasCustomStorage }}
+
+==== Storage
+
+{{ if .AccessMethod }}* Access method: {{ .AccessMethod }}
+{{ end -}}
+{{ range $name, $value := .StorageParams -}}
+* {{ $name }}: {{ $value }}
+{{ end }}
+{{- end }}
+{{ end }}
+== Enums
+{{ range .Data.Enums }}
+[[{{ anchor .Name }}]]
+=== {{ snakeToTitle .Name }}
+{{ if .Transitions }}
+{{ .Description }}
+
+|===
+| From | To
+
+{{ range .Transitions -}}
+| {{ .From }} | {{ .To }}
+{{ end -}}
+|===
+
+[plantuml]
+----
+{{ .StateDiagram }}----
+{{ else if .Description }}
+{{ .Description }}
+{{ else }}
+{{ range .Values -}}
+* {{ . }}
+{{ end }}
+{{- end }}
+{{- end }}
+{{- if .Data.Functions }}
+
+== Functions
+{{ range .Data.Functions }}
+=== {{ .Name }}
+
+` + "`{{ adocescape .Signature }}`" + `{{ if .Returns }} returns ` + "`{{ adocescape .Returns }}`" + `{{ end }}
+
+{{ .Description }}
+
+* Kind: {{ .Kind }}
+* Language: {{ .Language }}
+* Volatility: {{ .Volatility }}
+{{ end }}
+{{- end }}
+{{- if .Conventions }}
+
+== Naming Conventions
+
+|===
+| Pattern | Meaning | Columns
+
+{{ range .Conventions -}}
+| ` + "`{{ adocescape .Pattern }}`" + ` | {{ .Meaning }} | {{ join .Examples ", " }}{{ if .More }} and {{ .More }} more{{ end }}
+{{ end -}}
+|===
+{{- end }}
+`
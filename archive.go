@@ -0,0 +1,59 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeArchive renders the outputs into a scratch directory, then packages
+// everything written there into a zip file at filename. Output names become
+// paths inside the archive.
+func writeArchive(schema *Schema, outputs Outputs, filename string) error {
+	dir, err := ioutil.TempDir("", "pgdoc")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	archived := outputs
+	archived.Archive = ""
+	if err := writeOutputs(schema, archived, dir); err != nil {
+		return err
+	}
+
+	return withWriter(filename, func(w io.Writer) error {
+		archive := zip.NewWriter(w)
+		if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(name)
+			header.Method = zip.Deflate
+			out, err := archive.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			in, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+			_, err = io.Copy(out, in)
+			return err
+		}); err != nil {
+			return err
+		}
+		return archive.Close()
+	})
+}
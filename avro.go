@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name string      `json:"name"`
+	Type interface{} `json:"type"`
+	Doc  string      `json:"doc,omitempty"`
+
+	// Default is only set for nullable fields, which default to null
+	Default *json.RawMessage `json:"default,omitempty"`
+}
+
+// avroNull is the default of nullable fields
+var avroNull = json.RawMessage("null")
+
+// avroTypes are the Avro types for each kind, with logical types following
+// what Debezium produces. Kinds missing here are strings.
+var avroTypes = map[typeKind]interface{}{
+	kindInteger:    "int",
+	kindBigInteger: "long",
+	kindFloat:      "double",
+	kindBoolean:    "boolean",
+	kindTimestamp:  map[string]string{"type": "long", "logicalType": "timestamp-micros"},
+	kindDate:       map[string]string{"type": "int", "logicalType": "date"},
+	kindTime:       map[string]string{"type": "long", "logicalType": "time-micros"},
+	kindUUID:       map[string]string{"type": "string", "logicalType": "uuid"},
+	kindBytes:      "bytes",
+	kindArray:      map[string]string{"type": "array", "items": "string"},
+}
+
+// avroDump writes an Avro record schema per table to dir, named after the
+// table
+func avroDump(schema *Schema, dir string, namespace string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	enums := enumsByName(schema)
+	for _, table := range schema.Tables {
+		record := avroRecord{
+			Type:      "record",
+			Name:      camelCase(table.Name, true),
+			Namespace: namespace,
+			Doc:       table.Description,
+			Fields:    []avroField{},
+		}
+
+		// Named types can only be defined once in a schema, later uses
+		// refer to them by name
+		defined := map[string]bool{}
+		for _, col := range table.allColumns() {
+			field := avroField{
+				Name: protoFieldName(col.Name),
+				Doc:  col.Description,
+			}
+			if enum, ok := enums[col.DataType]; ok {
+				name := camelCase(enum.Name, true)
+				if defined[name] {
+					field.Type = name
+				} else {
+					symbols := []string{}
+					for _, value := range enum.Values {
+						symbols = append(symbols, protoFieldName(value))
+					}
+					field.Type = map[string]interface{}{"type": "enum", "name": name, "symbols": symbols}
+					defined[name] = true
+				}
+			} else {
+				field.Type = avroType(col.DataType)
+			}
+			if col.IsNullable {
+				field.Type = []interface{}{"null", field.Type}
+				field.Default = &avroNull
+			}
+			record.Fields = append(record.Fields, field)
+		}
+
+		if err := withWriter(filepath.Join(dir, table.Name+".avsc"), func(w io.Writer) error {
+			bytes, err := json.MarshalIndent(record, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func avroType(dataType string) interface{} {
+	kind := dataTypeKind(dataType)
+	if kind == kindDecimal {
+		var precision, scale int
+		if _, err := fmt.Sscanf(dataType, "Number(%d,%d)", &precision, &scale); err == nil && precision > 0 {
+			return map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": scale}
+		}
+		// Unconstrained numerics have no fixed precision for Avro
+		return "string"
+	}
+	if avro, ok := avroTypes[kind]; ok {
+		return avro
+	}
+	return "string"
+}
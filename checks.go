@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+)
+
+// addColumnChecks gives each column the CHECK constraints which refer to it
+// alone, so they can be shown alongside it
+func addColumnChecks(schema *Schema) {
+	for tableIdx := range schema.Tables {
+		table := &schema.Tables[tableIdx]
+		for _, check := range table.CheckConstraints {
+			if len(check.Columns) != 1 {
+				continue
+			}
+			for _, cols := range [][]ColumnDefinition{table.KeyColumns, table.Columns} {
+				for idx := range cols {
+					if cols[idx].Name == check.Columns[0] {
+						cols[idx].Checks = append(cols[idx].Checks, check.Definition)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TableChecks are the CHECK constraints over several columns, or none, which
+// aren't shown alongside a column. Those of snapshots from before their
+// columns were recorded are all here.
+func (t Table) TableChecks() []CheckConstraint {
+	checks := []CheckConstraint{}
+	for _, check := range t.CheckConstraints {
+		if len(check.Columns) != 1 {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// checkExpression is the expression of a CHECK constraint's definition, as
+// pg_get_constraintdef gives it: CHECK ((age >= 0)) NOT VALID
+func checkExpression(definition string) string {
+	expression := strings.TrimSuffix(strings.TrimSpace(definition), " NOT VALID")
+	expression = strings.TrimPrefix(expression, "CHECK ")
+	if strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") {
+		expression = expression[1 : len(expression)-1]
+	}
+	return expression
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// columnOrderKeysFirst lists key columns in their own section above
+	// the rest
+	columnOrderKeysFirst = "keys-first"
+
+	// columnOrderOrdinal lists every column in table definition order, with
+	// keys flagged where they fall
+	columnOrderOrdinal = "ordinal"
+
+	// columnOrderKeysFirstThenOrdinal lists keys at the top of a single list
+	// of columns, followed by the rest in definition order
+	columnOrderKeysFirstThenOrdinal = "keys-first-then-ordinal"
+)
+
+func checkColumnOrder(order string) error {
+	switch order {
+	case columnOrderKeysFirst, columnOrderOrdinal, columnOrderKeysFirstThenOrdinal:
+		return nil
+	default:
+		return fmt.Errorf("unknown column order %q", order)
+	}
+}
+
+// orderColumns rearranges each table's KeyColumns and Columns, which every
+// renderer uses as is
+func orderColumns(schema *Schema, order string) {
+	for idx, table := range schema.Tables {
+		keys := []ColumnDefinition{}
+		rest := []ColumnDefinition{}
+		for _, col := range table.allColumns() {
+			if col.IsKey {
+				keys = append(keys, col)
+			} else {
+				rest = append(rest, col)
+			}
+		}
+		byOrdinal := func(cols []ColumnDefinition) []ColumnDefinition {
+			sort.SliceStable(cols, func(i, j int) bool {
+				return cols[i].Ordinal < cols[j].Ordinal
+			})
+			return cols
+		}
+
+		switch order {
+		case columnOrderKeysFirst:
+			schema.Tables[idx].KeyColumns = byOrdinal(keys)
+			schema.Tables[idx].Columns = byOrdinal(rest)
+		case columnOrderOrdinal:
+			schema.Tables[idx].KeyColumns = []ColumnDefinition{}
+			schema.Tables[idx].Columns = byOrdinal(table.allColumns())
+		case columnOrderKeysFirstThenOrdinal:
+			schema.Tables[idx].KeyColumns = []ColumnDefinition{}
+			schema.Tables[idx].Columns = append(byOrdinal(keys), byOrdinal(rest)...)
+		}
+	}
+}
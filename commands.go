@@ -0,0 +1,643 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// command is one of pgdoc's subcommands
+type command struct {
+	name    string
+	summary string
+
+	// setup defines the command's flags, returning what runs it once they
+	// are parsed, with the arguments left over
+	setup func(fs *flag.FlagSet) func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	// In init, as allFlags sets every command up
+	commands = []command{
+		{"generate", "Write the schema's documentation, in any of the output formats (the default)", setupGenerate},
+		{"snapshot", "Write the schema as JSON, to render or diff against later", setupSnapshot},
+		{"diff", "List the changes from a snapshot to another, or to the database", setupDiff},
+		{"lint", "Report the gaps in the schema's documentation, exiting 1 if there are any", setupLint},
+		{"serve", "Serve the schema's documentation as HTML, read afresh for each request", setupServe},
+	}
+}
+
+// runCommand runs the subcommand named by the first argument, generate when
+// it is a flag or there are none, so pgdoc's flags alone still work
+func runCommand(args []string) error {
+	name := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		commandsUsage(os.Stdout)
+		return nil
+	}
+
+	var cmd *command
+	for idx := range commands {
+		if commands[idx].name == name {
+			cmd = &commands[idx]
+		}
+	}
+	if cmd == nil {
+		commandsUsage(os.Stderr)
+		return fmt.Errorf("unknown command %s", name)
+	}
+
+	fs := flag.NewFlagSet("pgdoc "+cmd.name, flag.ExitOnError)
+	settings := defineSettingsFlags(fs)
+	run := cmd.setup(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *settings.configSchemaFile != "" {
+		return withWriter(*settings.configSchemaFile, func(w io.Writer) error {
+			return configSchemaDump(allFlags(), w)
+		})
+	}
+	if err := settings.load(fs); err != nil {
+		return err
+	}
+	return run(fs.Args())
+}
+
+func commandsUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: pgdoc [command] [flags]")
+	fmt.Fprintln(w)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "pgdoc [command] -h lists the command's flags")
+}
+
+// allFlags defines every command's flags on one FlagSet, as the config file
+// is shared by them all
+func allFlags() *flag.FlagSet {
+	all := flag.NewFlagSet("pgdoc", flag.ContinueOnError)
+	for _, cmd := range commands {
+		fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		defineSettingsFlags(fs)
+		cmd.setup(fs)
+		fs.VisitAll(func(f *flag.Flag) {
+			if all.Lookup(f.Name) == nil {
+				all.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+	}
+	return all
+}
+
+// settingsFlags are the flags of every command about where the others are
+// set
+type settingsFlags struct {
+	configFile       *string
+	configSchemaFile *string
+	verbose          *bool
+}
+
+func defineSettingsFlags(fs *flag.FlagSet) settingsFlags {
+	return settingsFlags{
+		configFile:       fs.String("config", "", "YAML config file of flag values, by default pgdoc.yaml or .pgdoc.yaml in this directory or a parent"),
+		configSchemaFile: fs.String("config-schema", "", "Write a JSON Schema of the config file to this file and exit"),
+		verbose:          fs.Bool("verbose", false, "Log more about what pgdoc is doing"),
+	}
+}
+
+// load fills in the flags not given on the command line from the
+// environment and config file
+func (sf settingsFlags) load(fs *flag.FlagSet) error {
+	loadedConfig, err := loadSettings(fs, allFlags(), *sf.configFile)
+	if err != nil {
+		return err
+	}
+	if *sf.verbose {
+		if loadedConfig == "" {
+			log.Printf("No config file")
+		} else {
+			log.Printf("Using config file %s", loadedConfig)
+		}
+	}
+	return nil
+}
+
+// sourceFlags are the flags of the commands which read a schema, saying
+// where from, what to read and how to prepare it
+type sourceFlags struct {
+	schemas            arrayFlags
+	include            arrayFlags
+	exclude            arrayFlags
+	migrationTables    arrayFlags
+	profileColumns     arrayFlags
+	redact             arrayFlags
+	excludeColumns     arrayFlags
+	pgURLs             arrayFlags
+	polymorphicTargets arrayFlags
+
+	allSchemas            *bool
+	excludeMigrations     *bool
+	privileges            *bool
+	publications          *bool
+	eventTriggers         *bool
+	profileConcurrency    *int
+	profileTimeout        *time.Duration
+	sample                *int
+	maintenance           *bool
+	inheritViewComments   *bool
+	sizes                 *bool
+	stats                 *bool
+	service               *string
+	maxReplicaLag         *time.Duration
+	replicaLagWarnOnly    *bool
+	fromJSON              *string
+	descriptionsFile      *string
+	descriptionsFillOnly  *bool
+	enumStateMachines     *bool
+	logicalKeysFile       *string
+	columnOrder           *string
+	polymorphic           *bool
+	polymorphicTypeSuffix *string
+	polymorphicIDSuffix   *string
+
+	// postgresURL is resolved once, as doing so unsets PGSERVICE and
+	// PGSERVICEFILE, and serve and -watch read the schema again and again
+	postgresURL *string
+}
+
+func defineSourceFlags(fs *flag.FlagSet) *sourceFlags {
+	sf := &sourceFlags{}
+	fs.Var(&sf.schemas, "schema", "Postgres schema to document, repeat to document several with qualified names (default public)")
+	sf.allSchemas = fs.Bool("all-schemas", false, "Document every schema in the database other than postgres' own, instead of -schema")
+	fs.Var(&sf.include, "include", "Tables and views to document, by name, glob like tmp_* or regular expression like .*_archive$ (default all)")
+	fs.Var(&sf.exclude, "exclude", "Tables and views to leave out, by name, glob like tmp_* or regular expression like .*_archive$")
+	sf.excludeMigrations = fs.Bool("exclude-schema-migrations", false, "Exclude migration tool bookkeeping tables")
+	sf.privileges = fs.Bool("privileges", false, "Document the privileges granted on each table")
+	sf.publications = fs.Bool("publications", false, "Document logical replication publications and the tables they stream")
+	sf.eventTriggers = fs.Bool("event-triggers", false, "Document the database's event triggers")
+	fs.Var(&sf.profileColumns, "profile", "Table, or table.column, to profile with live queries for the count of rows, nulls and distinct values and the min and max")
+	sf.profileConcurrency = fs.Int("profile-concurrency", 4, "How many -profile queries to run at once")
+	sf.profileTimeout = fs.Duration("profile-timeout", 30*time.Second, "Skip -profile columns whose query takes longer than this, 0 for no limit")
+	sf.sample = fs.Int("sample", 0, "Document this many example rows of each table")
+	fs.Var(&sf.redact, "redact", "Column, or table.column, whose data -sample, -stats and -profile hide")
+	fs.Var(&sf.excludeColumns, "exclude-column", "Column, or table.column, to leave out of the tables and views")
+	sf.maintenance = fs.Bool("maintenance", false, "Document when each table was last vacuumed and analyzed")
+	sf.inheritViewComments = fs.Bool("inherit-view-comments", false, "Describe view columns without a comment with the comment of the table column they select")
+	sf.sizes = fs.Bool("sizes", false, "Document each table's estimated row count and size on disk")
+	sf.stats = fs.Bool("stats", false, "Document the null fraction, distinct values and most common values of each column from pg_stats")
+	fs.Var(&sf.migrationTables, "schema-migrations-table", "Replaces the built in list of tables dropped by -exclude-schema-migrations")
+	fs.Var(&sf.pgURLs, "postgres", "Postgres URL, repeat to document several databases (default DATABASE_URL, or the PG* environment variables)")
+	sf.service = fs.String("service", "", "Connect to this service from ~/.pg_service.conf instead of -postgres (default PGSERVICE)")
+	sf.maxReplicaLag = fs.Duration("max-replica-lag", 0, "Refuse to document a replica lagging its primary by more than this, e.g. 30s")
+	sf.replicaLagWarnOnly = fs.Bool("replica-lag-warn-only", false, "Warn rather than fail when -max-replica-lag is exceeded")
+	sf.fromJSON = fs.String("from-json", "", "Render from a file written by -json instead of a database")
+	sf.descriptionsFile = fs.String("descriptions-file", "", "YAML or JSON map of table and table.column to descriptions which override the database comments")
+	sf.descriptionsFillOnly = fs.Bool("descriptions-fill-only", false, "Only use -descriptions-file where the database has no comment")
+	sf.enumStateMachines = fs.Bool("enum-state-machines", false, "Parse 'from -> to, to' transition lines in enum comments and document them as state machines")
+	sf.logicalKeysFile = fs.String("logical-keys", "", "JSON file declaring keys for relations without constraints, e.g. materialized views")
+	sf.columnOrder = fs.String("column-order", columnOrderKeysFirst, "Column order: keys-first, ordinal or keys-first-then-ordinal")
+	sf.polymorphic = fs.Bool("polymorphic", false, "Detect <name>_type / <name>_id polymorphic associations")
+	sf.polymorphicTypeSuffix = fs.String("polymorphic-type-suffix", "_type", "Suffix of the type column in a polymorphic association")
+	sf.polymorphicIDSuffix = fs.String("polymorphic-id-suffix", "_id", "Suffix of the id column in a polymorphic association")
+	fs.Var(&sf.polymorphicTargets, "polymorphic-target", "Tables a polymorphic association may reference, as name=table,table")
+	return sf
+}
+
+// config is what to introspect, connecting to the first -postgres
+func (sf *sourceFlags) config() (Config, error) {
+	exclude := sf.exclude
+	if *sf.excludeMigrations {
+		migrationTables := sf.migrationTables
+		if len(migrationTables) == 0 {
+			migrationTables = schemaMigrationTables
+		}
+		exclude = append(exclude, migrationTables...)
+	}
+
+	config := Config{
+		Schemas:             []string(sf.schemas),
+		AllSchemas:          *sf.allSchemas,
+		Include:             []string(sf.include),
+		Exclude:             []string(exclude),
+		MaxReplicaLag:       *sf.maxReplicaLag,
+		ReplicaLagWarnOnly:  *sf.replicaLagWarnOnly,
+		Privileges:          *sf.privileges,
+		Publications:        *sf.publications,
+		EventTriggers:       *sf.eventTriggers,
+		Stats:               *sf.stats,
+		Sizes:               *sf.sizes,
+		Maintenance:         *sf.maintenance,
+		InheritViewComments: *sf.inheritViewComments,
+		Sample:              *sf.sample,
+		ExcludeColumns:      []string(sf.excludeColumns),
+		Redact:              []string(sf.redact),
+		Profile: ProfileOptions{
+			Columns:     []string(sf.profileColumns),
+			Concurrency: *sf.profileConcurrency,
+			Timeout:     *sf.profileTimeout,
+		},
+	}
+	if sf.postgresURL == nil {
+		firstURL := ""
+		if len(sf.pgURLs) > 0 {
+			firstURL = sf.pgURLs[0]
+		}
+		postgresURL, err := connectionString(firstURL, *sf.service)
+		if err != nil {
+			return Config{}, err
+		}
+		sf.postgresURL = &postgresURL
+	}
+	config.PostgresURL = *sf.postgresURL
+	return config, nil
+}
+
+// schemaOptions are the post processing steps the flags ask for
+func (sf *sourceFlags) schemaOptions() (SchemaOptions, error) {
+	schemaOptions := SchemaOptions{
+		ColumnOrder:       *sf.columnOrder,
+		EnumStateMachines: *sf.enumStateMachines,
+	}
+	if err := checkColumnOrder(*sf.columnOrder); err != nil {
+		return SchemaOptions{}, err
+	}
+
+	if *sf.descriptionsFile != "" {
+		descriptions, err := readDescriptions(*sf.descriptionsFile)
+		if err != nil {
+			return SchemaOptions{}, err
+		}
+		schemaOptions.Descriptions = descriptions
+		schemaOptions.DescriptionsFillOnly = *sf.descriptionsFillOnly
+	}
+
+	if *sf.logicalKeysFile != "" {
+		keys, err := readLogicalKeys(*sf.logicalKeysFile)
+		if err != nil {
+			return SchemaOptions{}, err
+		}
+		schemaOptions.LogicalKeys = keys
+	}
+
+	if *sf.polymorphic {
+		targets, err := parsePolymorphicTargets(sf.polymorphicTargets)
+		if err != nil {
+			return SchemaOptions{}, err
+		}
+		schemaOptions.Polymorphic = &PolymorphicOptions{
+			TypeSuffix: *sf.polymorphicTypeSuffix,
+			IDSuffix:   *sf.polymorphicIDSuffix,
+			Targets:    targets,
+		}
+	}
+	return schemaOptions, nil
+}
+
+// read reads the schema from the database, or -from-json, and prepares it
+func (sf *sourceFlags) read() (*Schema, *Report, error) {
+	if len(sf.pgURLs) > 1 {
+		return nil, nil, fmt.Errorf("only generate documents several databases")
+	}
+	config, err := sf.config()
+	if err != nil {
+		return nil, nil, err
+	}
+	schemaOptions, err := sf.schemaOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var source SchemaSource = postgresSource{config: config}
+	if *sf.fromJSON != "" {
+		source = jsonFileSource{filename: *sf.fromJSON, excludeColumns: config.ExcludeColumns}
+	}
+
+	fullSchema, report, err := source.GetSchema()
+	if err != nil {
+		return nil, nil, err
+	}
+	logSkipped("", report)
+
+	if err := prepareSchema(fullSchema, schemaOptions); err != nil {
+		return nil, nil, err
+	}
+	return fullSchema, report, nil
+}
+
+func setupGenerate(fs *flag.FlagSet) func(args []string) error {
+	source := defineSourceFlags(fs)
+
+	outputDir := fs.String("output-dir", "", "Directory for per database outputs when documenting several databases")
+	driftOutFile := fs.String("drift", "", "MD report of schema drift between several databases")
+	sinceFile := fs.String("since", "", "JSON file written by -json to compare against, exits 2 if the schema has changed")
+	changelogOutFile := fs.String("changelog", "-", "MD Output File for the -since changelog")
+	reportOutFile := fs.String("report", "", "Introspection report Output File, with counts, timings and documentation gaps")
+	watch := fs.Bool("watch", false, "Keep running, regenerating the outputs whenever the schema changes")
+	watchOptions := WatchOptions{}
+	fs.DurationVar(&watchOptions.Interval, "watch-interval", 5*time.Second, "How often -watch reads the schema, or with -watch-channel checks the connection")
+	fs.StringVar(&watchOptions.Channel, "watch-channel", "", "LISTEN on this channel, notified by a DDL event trigger, and only read the schema for -watch when notified")
+
+	outputs := Outputs{}
+	fs.StringVar(&outputs.PUML, "puml", "", "PUML Output File")
+	fs.StringVar(&outputs.PNG, "png", "", "PNG Output File, the PUML diagram rendered by -plantuml-server")
+	fs.StringVar(&outputs.SVG, "svg", "", "SVG Output File, the PUML diagram rendered by -plantuml-server")
+	fs.StringVar(&outputs.PlantUMLServer, "plantuml-server", defaultPlantUMLServer, "PlantUML server to render -png and -svg with")
+	fs.StringVar(&outputs.JSON, "json", "", "JSON Output File")
+	fs.StringVar(&outputs.NDJSON, "ndjson", "", "NDJSON Output File, a line per table and enum")
+	fs.StringVar(&outputs.YAML, "yaml", "", "YAML Output File, the same document as -json")
+	fs.StringVar(&outputs.Markdown, "md", "", "MD Output File")
+	fs.StringVar(&outputs.MarkdownOptions.Template, "md-template", "", "Template file, or directory of templates, to write MD with instead of the built in one")
+	fs.StringVar(&outputs.MarkdownDir, "md-dir", "", "Directory to write an MD page per table and view to, with a README.md index")
+	fs.StringVar(&outputs.AsciiDoc, "adoc", "", "AsciiDoc Output File")
+	fs.StringVar(&outputs.LaTeX, "latex", "", "LaTeX Output File")
+	fs.StringVar(&outputs.HTML, "html", "", "Single page HTML Output File")
+	fs.BoolVar(&outputs.MarkdownOptions.HTMLRawDescriptions, "html-raw-descriptions", false, "Render descriptions in -html and -site without sanitizing them, for trusted comments")
+	fs.StringVar(&outputs.Confluence, "confluence", "", "Confluence storage format Output File")
+	fs.StringVar(&outputs.Docusaurus, "docusaurus", "", "Directory to write Docusaurus MDX pages to, one per table")
+	fs.StringVar(&outputs.MkDocs, "mkdocs", "", "Directory to write a MkDocs project to, a page per table and mkdocs.yml")
+	fs.StringVar(&outputs.Hugo, "hugo", "", "Directory to write a Hugo content section to, a page per table")
+	fs.StringVar(&outputs.Site, "site", "", "Directory to write a static HTML site to, one page per table and enum")
+	fs.StringVar(&outputs.CSV, "csv", "", "Directory to write CSV data dictionary files to")
+	fs.StringVar(&outputs.GraphQL, "graphql", "", "GraphQL SDL Output File")
+	fs.StringVar(&outputs.Proto, "proto", "", "Protobuf (proto3) Output File")
+	fs.StringVar(&outputs.ProtoPackage, "proto-package", "schema", "Package name for -proto")
+	fs.StringVar(&outputs.JSONSchema, "json-schema", "", "Directory to write a JSON Schema document per table to")
+	fs.StringVar(&outputs.Avro, "avro", "", "Directory to write an Avro record schema per table to")
+	fs.StringVar(&outputs.AvroNamespace, "avro-namespace", "", "Namespace for the -avro record schemas")
+	fs.StringVar(&outputs.TypeScript, "typescript", "", "TypeScript declarations (.d.ts) Output File")
+	fs.StringVar(&outputs.Go, "go", "", "Go structs Output File")
+	fs.StringVar(&outputs.GoPackage, "go-package", "models", "Package name for -go")
+	fs.StringVar(&outputs.OpenAPI, "openapi", "", "OpenAPI components Output File, YAML for .yaml or .yml and JSON otherwise")
+	fs.StringVar(&outputs.DBT, "dbt", "", "dbt schema.yml Output File declaring the tables as a source")
+	fs.StringVar(&outputs.DBTSource, "dbt-source", "public", "Source name for -dbt")
+	fs.StringVar(&outputs.Liquibase, "liquibase", "", "Liquibase XML baseline changelog Output File")
+	fs.StringVar(&outputs.SQLite, "sqlite", "", "SQLite catalog Output File")
+	fs.StringVar(&outputs.PUMLJSON, "puml-json", "", "PUML @startjson Output File")
+	fs.StringVar(&outputs.PUMLJSONTable, "puml-json-table", "", "Render only this table in -puml-json")
+	fs.StringVar(&outputs.DBML, "dbml", "", "DBML Output File")
+	fs.StringVar(&outputs.D2, "d2", "", "D2 diagram Output File")
+	fs.StringVar(&outputs.DrawIO, "drawio", "", "diagrams.net (draw.io) Output File")
+	fs.StringVar(&outputs.Mermaid, "mermaid", "", "Mermaid erDiagram Output File")
+	fs.StringVar(&outputs.MermaidFlow, "mermaid-flow", "", "Mermaid flowchart of table dependencies Output File")
+	fs.StringVar(&outputs.ViewsPUML, "views-puml", "", "PUML diagram of which views select from which tables and views Output File")
+	fs.StringVar(&outputs.ViewsMermaid, "views-mermaid", "", "Mermaid flowchart of which views select from which tables and views Output File")
+	fs.BoolVar(&outputs.CollapsePartitions, "collapse-partitions", false, "Show partitioned tables without their partitions in diagrams")
+
+	fs.StringVar(&outputs.Archive, "archive", "", "Zip file to package the other outputs into instead of writing them out")
+
+	headerFile := fs.String("header-file", "", "File written verbatim at the top of the markdown, AsciiDoc, LaTeX, HTML, Confluence and PUML outputs and of each page of the multi-page ones (after @startuml in PUML)")
+	footerFile := fs.String("footer-file", "", "File written verbatim at the end of the markdown, AsciiDoc, LaTeX, HTML, Confluence and PUML outputs and of each page of the multi-page ones (before @enduml in PUML)")
+
+	mdConventions := fs.Bool("md-conventions", false, "Include a glossary of column naming conventions in MD")
+	fs.IntVar(&outputs.MarkdownOptions.TopHubs, "top-hubs", 0, "List the N most referenced tables at the top of MD")
+	var linkTypes arrayFlags
+	fs.Var(&linkTypes, "link-type", "Type to link to its definition in MD even if it doesn't look custom")
+	var noLinkTypes arrayFlags
+	fs.Var(&noLinkTypes, "no-link-type", "Type never to link to a definition in MD")
+	conventionsFile := fs.String("conventions-file", "", "JSON list of {pattern, meaning} naming conventions for -md-conventions")
+
+	pumlNoColumns := fs.Bool("puml-skip-columns", false, "Skip columns in PUML output")
+	fs.BoolVar(&outputs.PUMLOptions.IncludeDataTypes, "puml-include-types", false, "Include data types in PUML")
+	fs.BoolVar(&outputs.PUMLOptions.IncludeDescriptions, "puml-include-descriptions", false, "Attach table comments to entities as notes in PUML")
+	fs.IntVar(&outputs.PUMLOptions.DescriptionWidth, "puml-description-width", 60, "Wrap PUML description notes to this many characters")
+
+	mermaidNoColumns := fs.Bool("mermaid-skip-columns", false, "Skip columns in Mermaid output")
+	fs.BoolVar(&outputs.MermaidOptions.IncludeDataTypes, "mermaid-include-types", false, "Include data types in Mermaid, otherwise every column is shown as '"+mermaidTypePlaceholder+"'")
+
+	return func(args []string) error {
+		outputs.PUMLOptions.IncludeColumns = !*pumlNoColumns
+		outputs.MermaidOptions.IncludeColumns = !*mermaidNoColumns
+		outputs.MarkdownOptions.LinkTypes = linkTypes
+		outputs.MarkdownOptions.NoLinkTypes = noLinkTypes
+
+		for _, file := range []struct {
+			filename string
+			into     *string
+		}{
+			{*headerFile, &outputs.Header},
+			{*footerFile, &outputs.Footer},
+		} {
+			if file.filename == "" {
+				continue
+			}
+			data, err := ioutil.ReadFile(file.filename)
+			if err != nil {
+				return err
+			}
+			*file.into = string(data)
+		}
+		outputs.PUMLOptions.Header = outputs.Header
+		outputs.PUMLOptions.Footer = outputs.Footer
+		outputs.MarkdownOptions.Header = outputs.Header
+		outputs.MarkdownOptions.Footer = outputs.Footer
+
+		if *mdConventions {
+			outputs.MarkdownOptions.NamingConventions = defaultNamingConventions
+			if *conventionsFile != "" {
+				conventions, err := readNamingConventions(*conventionsFile)
+				if err != nil {
+					return err
+				}
+				outputs.MarkdownOptions.NamingConventions = conventions
+			}
+		}
+
+		if *watch && (len(source.pgURLs) > 1 || *sinceFile != "") {
+			return fmt.Errorf("-watch documents a single database, without -since")
+		}
+		if len(source.pgURLs) > 1 && (*sinceFile != "" || *reportOutFile != "") {
+			return fmt.Errorf("-since and -report document a single database, not several -postgres")
+		}
+
+		if len(source.pgURLs) > 1 {
+			config, err := source.config()
+			if err != nil {
+				return err
+			}
+			schemaOptions, err := source.schemaOptions()
+			if err != nil {
+				return err
+			}
+			return documentDatabases(source.pgURLs, config, schemaOptions, outputs, *outputDir, *driftOutFile)
+		}
+
+		generate := func(fullSchema *Schema, report *Report) error {
+			if err := writeOutputs(fullSchema, outputs, ""); err != nil {
+				return err
+			}
+			if *reportOutFile == "" {
+				return nil
+			}
+			return withWriter(*reportOutFile, func(w io.Writer) error {
+				return reportDump(report, w)
+			})
+		}
+
+		if *watch {
+			if watchOptions.Channel != "" {
+				if *source.fromJSON != "" {
+					return fmt.Errorf("-watch-channel needs a database, not -from-json")
+				}
+				config, err := source.config()
+				if err != nil {
+					return err
+				}
+				watchOptions.PostgresURL = config.PostgresURL
+			}
+			return watchSchema(source.read, generate, watchOptions)
+		}
+
+		fullSchema, report, err := source.read()
+		if err != nil {
+			return err
+		}
+
+		if err := generate(fullSchema, report); err != nil {
+			return err
+		}
+
+		if *sinceFile != "" {
+			baseline, _, err := jsonFileSource{filename: *sinceFile}.GetSchema()
+			if err != nil {
+				return err
+			}
+			return writeChangelog(baseline, fullSchema, *changelogOutFile)
+		}
+		return nil
+	}
+}
+
+// exitChanged is the exit code when a changelog has changes, telling them
+// apart from the errors log.Fatal exits 1 for
+const exitChanged = 2
+
+// writeChangelog writes the changes from baseline to schema, exiting with
+// exitChanged if there are any
+func writeChangelog(baseline *Schema, schema *Schema, filename string) error {
+	changes := diffSchemas(baseline, schema)
+	if err := withWriter(filename, func(w io.Writer) error {
+		return changelogDump(changes, w)
+	}); err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		os.Exit(exitChanged)
+	}
+	return nil
+}
+
+func setupSnapshot(fs *flag.FlagSet) func(args []string) error {
+	source := defineSourceFlags(fs)
+	outFile := fs.String("json", "-", "JSON Output File")
+
+	return func(args []string) error {
+		fullSchema, _, err := source.read()
+		if err != nil {
+			return err
+		}
+		return withWriter(*outFile, func(w io.Writer) error {
+			return jsonDump(fullSchema, w)
+		})
+	}
+}
+
+func setupDiff(fs *flag.FlagSet) func(args []string) error {
+	source := defineSourceFlags(fs)
+	outFile := fs.String("changelog", "-", "MD Output File for the changelog")
+
+	return func(args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("usage: pgdoc diff [flags] from.json [to.json], comparing to the database without to.json")
+		}
+		baseline, _, err := jsonFileSource{filename: args[0]}.GetSchema()
+		if err != nil {
+			return err
+		}
+		var fullSchema *Schema
+		if len(args) == 2 {
+			fullSchema, _, err = jsonFileSource{filename: args[1]}.GetSchema()
+		} else {
+			fullSchema, _, err = source.read()
+		}
+		if err != nil {
+			return err
+		}
+		return writeChangelog(baseline, fullSchema, *outFile)
+	}
+}
+
+func setupLint(fs *flag.FlagSet) func(args []string) error {
+	source := defineSourceFlags(fs)
+	outFile := fs.String("report", "-", "Introspection report Output File, with counts, timings and documentation gaps")
+
+	return func(args []string) error {
+		_, report, err := source.read()
+		if err != nil {
+			return err
+		}
+		if err := withWriter(*outFile, func(w io.Writer) error {
+			return reportDump(report, w)
+		}); err != nil {
+			return err
+		}
+		// What couldn't be read isn't a gap in the documentation
+		for _, warning := range report.Warnings {
+			if warning.Kind != warnSkipped {
+				os.Exit(1)
+			}
+		}
+		return nil
+	}
+}
+
+func setupServe(fs *flag.FlagSet) func(args []string) error {
+	source := defineSourceFlags(fs)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+
+	return func(args []string) error {
+		serveDump := func(contentType string, dump func(*Schema, io.Writer) error) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				fullSchema, _, err := source.read()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", contentType)
+				if err := dump(fullSchema, w); err != nil {
+					log.Printf("Serving %s: %s", r.URL.Path, err.Error())
+				}
+			}
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/schema.json", serveDump("application/json", jsonDump))
+		mux.Handle("/schema.md", serveDump("text/markdown; charset=utf-8", func(schema *Schema, w io.Writer) error {
+			return mdDump(schema, w, MarkdownOptions{})
+		}))
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			serveDump("text/html; charset=utf-8", func(schema *Schema, w io.Writer) error {
+				return htmlDump(schema, w, MarkdownOptions{})
+			})(w, r)
+		})
+
+		log.Printf("Serving on http://%s", *addr)
+		return http.ListenAndServe(*addr, mux)
+	}
+}
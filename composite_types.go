@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// CompositeType is a type created with CREATE TYPE ... AS (...). Columns of
+// one are custom types named after it, like enums.
+type CompositeType struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Attributes  []ColumnDefinition `json:"attributes"`
+}
+
+// getCompositeTypes reads the standalone composite types in the schema,
+// leaving out the row types every table has. Attribute comments are filled
+// in with the columns'.
+func getCompositeTypes(ctx context.Context, db *sqrlx.Wrapper, schema string) ([]CompositeType, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT t.typname,
+	COALESCE(pg_catalog.obj_description(t.oid, 'pg_type'), '')
+	FROM pg_catalog.pg_type t
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+	JOIN pg_catalog.pg_class c ON c.oid = t.typrelid
+	WHERE n.nspname = $1 AND t.typtype = 'c' AND c.relkind = 'c'
+	ORDER BY t.typname`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []CompositeType{}
+	for rows.Next() {
+		compositeType := CompositeType{}
+		if err := rows.Scan(&compositeType.Name, &compositeType.Description); err != nil {
+			return nil, err
+		}
+		types = append(types, compositeType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for idx, compositeType := range types {
+		attributes, err := getRelationColumns(ctx, db, schema, compositeType.Name)
+		if err != nil {
+			return nil, err
+		}
+		types[idx].Attributes = attributes
+	}
+	return types, nil
+}
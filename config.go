@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configFileNames are looked for in the working directory and its parents
+// when -config isn't given
+var configFileNames = []string{"pgdoc.yaml", ".pgdoc.yaml"}
+
+// envPrefix is prepended to the upper cased flag name to set a flag from
+// the environment, e.g. PGDOC_MD for -md
+const envPrefix = "PGDOC_"
+
+// loadSettings fills in flags which weren't given on the command line, first
+// from PGDOC_ environment variables and then from the config file. The
+// config file is shared by every command, so settings which are flags of
+// any of them, in known, are allowed. It returns the config file used, if
+// any.
+func loadSettings(fs *flag.FlagSet, known *flag.FlagSet, configFile string) (string, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || envErr != nil {
+			return
+		}
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if err := fs.Set(f.Name, value); err != nil {
+				envErr = fmt.Errorf("%s: %w", name, err)
+			}
+			set[f.Name] = true
+		}
+	})
+	if envErr != nil {
+		return "", envErr
+	}
+
+	// The environment may have named the config file
+	if configFile == "" {
+		if f := fs.Lookup("config"); f != nil {
+			configFile = f.Value.String()
+		}
+	}
+	if configFile == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		configFile = findConfigFile(wd)
+		if configFile == "" {
+			return "", nil
+		}
+	}
+
+	read, err := readConfigFile(configFile)
+	if err != nil {
+		return "", err
+	}
+	settings := map[string]interface{}{}
+	if err := flattenSettings(known, read, settings); err != nil {
+		return "", fmt.Errorf("%s: %w", configFile, err)
+	}
+	for name, value := range settings {
+		if set[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := setFlag(fs, name, value); err != nil {
+			return "", fmt.Errorf("%s: %w", configFile, err)
+		}
+	}
+	return configFile, nil
+}
+
+// findConfigFile walks up from dir to the root of the repository (the
+// directory holding .git) or the filesystem, returning the first config file
+// found
+func findConfigFile(dir string) string {
+	for {
+		for _, name := range configFileNames {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readConfigFile reads a YAML file whose keys are flag names
+func readConfigFile(filename string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("Reading %s: %w", filename, err)
+	}
+	return settings, nil
+}
+
+// flattenSettings copies the settings into flat, keyed by flag name. Keys
+// which aren't flags with a map value are sections grouping settings, like
+// connection or outputs, and are read into flat too.
+func flattenSettings(fs *flag.FlagSet, settings map[string]interface{}, flat map[string]interface{}) error {
+	for name, value := range settings {
+		if fs.Lookup(name) != nil {
+			if _, ok := flat[name]; ok {
+				return fmt.Errorf("setting %s is given twice", name)
+			}
+			flat[name] = value
+			continue
+		}
+		section, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return fmt.Errorf("unknown setting %s", name)
+		}
+		sectionSettings := map[string]interface{}{}
+		for key, sectionValue := range section {
+			sectionSettings[fmt.Sprint(key)] = sectionValue
+		}
+		if err := flattenSettings(fs, sectionSettings, flat); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// configSchemaDump writes a JSON Schema of the config file, for editors to
+// check and complete it with. Each flag is a property described by its
+// usage, and any other key is a section of more settings.
+func configSchemaDump(fs *flag.FlagSet, w io.Writer) error {
+	properties := map[string]interface{}{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || f.Name == "config-schema" {
+			return
+		}
+		property := map[string]interface{}{
+			"description": f.Usage,
+		}
+		switch value := f.Value.(type) {
+		case *arrayFlags:
+			property["anyOf"] = []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			}
+		case flag.Getter:
+			switch value.Get().(type) {
+			case bool:
+				property["type"] = "boolean"
+			case int, int64, uint, uint64:
+				property["type"] = "integer"
+			case float64:
+				property["type"] = "number"
+			default:
+				// Durations too, like 30s
+				property["type"] = "string"
+			}
+		default:
+			property["type"] = "string"
+		}
+		properties[f.Name] = property
+	})
+
+	schema := map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "pgdoc config file",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": map[string]interface{}{"$ref": "#"},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
+
+// setFlag sets a flag from a config value, lists set repeatable flags once
+// per item
+func setFlag(fs *flag.FlagSet, name string, value interface{}) error {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if err := setFlag(fs, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[interface{}]interface{}:
+		return fmt.Errorf("setting %s should be a value or a list", name)
+	case nil:
+		return nil
+	default:
+		if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+		return nil
+	}
+}
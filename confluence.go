@@ -0,0 +1,111 @@
+package main
+
+import (
+	"html/template"
+	"io"
+)
+
+// confluenceDump renders the schema in Confluence storage format, the XHTML
+// with macros that Confluence pages are saved as, so it can be pushed to a
+// page through the API or pasted into the source editor
+func confluenceDump(schema *Schema, w io.Writer, options MarkdownOptions) error {
+	tpl, err := template.New("confluence.xml").Funcs(htmlFuncs(options)).Parse(confluenceTemplate)
+	if err != nil {
+		return err
+	}
+	return tpl.Execute(w, schema)
+}
+
+var confluenceTemplate = `
+{{- define "anchor" }}<ac:structured-macro ac:name="anchor"><ac:parameter ac:name="">{{ anchor . }}</ac:parameter></ac:structured-macro>{{ end }}
+{{- define "link" }}<ac:link ac:anchor="{{ anchor . }}"><ac:plain-text-link-body>{{ . }}</ac:plain-text-link-body></ac:link>{{ end }}
+{{- define "type" }}{{ if linkable . }}<ac:link ac:anchor="{{ anchor .ElementType }}"><ac:plain-text-link-body>{{ .DataType }}</ac:plain-text-link-body></ac:link>{{ else }}{{ .DataType }}{{ end }}{{ end -}}
+<ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">2</ac:parameter></ac:structured-macro>
+<h1>Tables</h1>
+{{ range .Tables }}
+<h2>{{ template "anchor" .Name }}{{ snakeToTitle .Name }}</h2>
+{{- if or .FanIn .FanOut }}
+<p>Referenced by {{ .FanIn }}, references {{ .FanOut }}</p>
+{{- end }}
+{{- range paragraphs .Description }}
+<p>{{ . }}</p>
+{{- end }}
+<table>
+<tbody>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+{{- range .KeyColumns }}
+<tr><td>{{ .Name }} (KEY)</td><td>{{ template "type" . }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+{{- range .Columns }}
+<tr><td>{{ .Name }}{{ if .IsKey }} (KEY){{ end }}{{ if .Generated }} (GENERATED){{ end }}</td><td>{{ template "type" . }}</td><td>{{ .Description }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- if or .ForeignKeys .Polymorphic }}
+<ul>
+{{- range .ForeignKeys }}
+<li>{{ .Name }}{{ if .Logical }} (logical){{ end }}: {{ .ColumnList }} → {{ template "link" .RefTable }}.{{ .RefColumnList }}</li>
+{{- end }}
+{{- range .Polymorphic }}
+<li>{{ .Name }} (polymorphic on {{ .TypeColumn }}, {{ .IDColumn }}{{ if .Targets }}: {{ join .Targets ", " }}{{ end }})</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .RLSEnabled }}
+<h3>Security Policies</h3>
+<p>Row level security is enabled{{ if .RLSForced }} and forced for the table owner{{ end }}.</p>
+{{- if .DeniesAll }}
+<ac:structured-macro ac:name="warning"><ac:rich-text-body><p>There are no policies, so every row is hidden.</p></ac:rich-text-body></ac:structured-macro>
+{{- else }}
+<table>
+<tbody>
+<tr><th>Policy</th><th>Command</th><th>Roles</th><th>Type</th><th>Using</th><th>With Check</th></tr>
+{{- range .Policies }}
+<tr><td>{{ .Name }}</td><td>{{ .Command }}</td><td>{{ join .Roles ", " }}</td><td>{{ if .Permissive }}permissive{{ else }}restrictive{{ end }}</td><td><code>{{ .Using }}</code></td><td><code>{{ .WithCheck }}</code></td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- end }}
+{{- end }}
+{{ end }}
+{{- if .Enums }}
+<h1>Enums</h1>
+{{ range .Enums }}
+<h2>{{ template "anchor" .Name }}{{ snakeToTitle .Name }}</h2>
+{{- range paragraphs .Description }}
+<p>{{ . }}</p>
+{{- end }}
+{{- if .Transitions }}
+<table>
+<tbody>
+<tr><th>From</th><th>To</th></tr>
+{{- range .Transitions }}
+<tr><td>{{ .From }}</td><td>{{ .To }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+{{- else }}
+<ul>
+{{- range .Values }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{ end }}
+{{- end }}
+{{- if .Functions }}
+<h1>Functions</h1>
+{{ range .Functions }}
+<h2>{{ .Name }}</h2>
+<p><code>{{ .Signature }}</code>{{ if .Returns }} returns <code>{{ .Returns }}</code>{{ end }}</p>
+{{- range paragraphs .Description }}
+<p>{{ . }}</p>
+{{- end }}
+<ul>
+<li>Kind: {{ .Kind }}</li>
+<li>Language: {{ .Language }}</li>
+<li>Volatility: {{ .Volatility }}</li>
+</ul>
+{{ end }}
+{{- end }}
+`
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// connectionString is what to connect with: the -postgres URL, or else a
+// service from the libpq service file, or else DATABASE_URL. When it is
+// empty lib/pq falls back on the PG* environment variables, like PGHOST and
+// PGUSER, and their defaults.
+func connectionString(postgresURL string, service string) (string, error) {
+	// lib/pq refuses to connect with these set, as it can't read service
+	// files itself, so they are done with here
+	defer func() {
+		os.Unsetenv("PGSERVICE")
+		os.Unsetenv("PGSERVICEFILE")
+	}()
+
+	if postgresURL != "" {
+		return postgresURL, nil
+	}
+	if service == "" {
+		service = os.Getenv("PGSERVICE")
+	}
+	if service != "" {
+		return serviceConnectionString(service)
+	}
+	return os.Getenv("DATABASE_URL"), nil
+}
+
+// serviceFiles are where libpq looks for service definitions, the user's
+// first
+func serviceFiles() []string {
+	files := []string{}
+	if file := os.Getenv("PGSERVICEFILE"); file != "" {
+		files = append(files, file)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".pg_service.conf"))
+	}
+	if dir := os.Getenv("PGSYSCONFDIR"); dir != "" {
+		files = append(files, filepath.Join(dir, "pg_service.conf"))
+	}
+	return files
+}
+
+// serviceConnectionString reads a service from the first service file
+// defining it, as a key/value connection string
+func serviceConnectionString(service string) (string, error) {
+	for _, filename := range serviceFiles() {
+		params, err := readService(filename, service)
+		if err != nil {
+			return "", err
+		}
+		if params == nil {
+			continue
+		}
+		pairs := []string{}
+		for _, param := range params {
+			value := strings.ReplaceAll(strings.ReplaceAll(param[1], `\`, `\\`), `'`, `\'`)
+			pairs = append(pairs, fmt.Sprintf("%s='%s'", param[0], value))
+		}
+		return strings.Join(pairs, " "), nil
+	}
+	return "", fmt.Errorf("service %s not found in %s", service, strings.Join(serviceFiles(), " or "))
+}
+
+// readService reads the key=value parameters of a [service] from an INI
+// style service file. They are nil when the file or the service doesn't
+// exist.
+func readService(filename string, service string) ([][2]string, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var params [][2]string
+	inService := false
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if inService {
+				break
+			}
+			inService = line[1:len(line)-1] == service
+			if inService {
+				params = [][2]string{}
+			}
+			continue
+		}
+		if !inService {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key=value", filename, lineNumber)
+		}
+		params = append(params, [2]string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+)
+
+// NamingConvention is a glob over column names and what it means, e.g.
+// `*_at` is a timestamp
+type NamingConvention struct {
+	Pattern string `json:"pattern"`
+	Meaning string `json:"meaning"`
+}
+
+var defaultNamingConventions = []NamingConvention{
+	{Pattern: "*_id", Meaning: "Identifier of a row in another table"},
+	{Pattern: "is_*", Meaning: "Boolean flag"},
+	{Pattern: "has_*", Meaning: "Boolean flag"},
+	{Pattern: "*_at", Meaning: "Timestamp of an event"},
+	{Pattern: "*_on", Meaning: "Date of an event"},
+	{Pattern: "*_count", Meaning: "Count"},
+}
+
+// maxConventionExamples limits the columns listed against each convention
+const maxConventionExamples = 5
+
+// ConventionUsage is a convention which appears in the schema
+type ConventionUsage struct {
+	NamingConvention
+	Count    int
+	Examples []string
+}
+
+// More is the number of matching columns not listed as examples
+func (cu ConventionUsage) More() int {
+	return cu.Count - len(cu.Examples)
+}
+
+// readNamingConventions reads a JSON list of conventions
+func readNamingConventions(filename string) ([]NamingConvention, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	conventions := []NamingConvention{}
+	if err := json.Unmarshal(data, &conventions); err != nil {
+		return nil, fmt.Errorf("Reading %s: %w", filename, err)
+	}
+	for _, convention := range conventions {
+		if _, err := path.Match(convention.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("Naming convention %q: %w", convention.Pattern, err)
+		}
+	}
+	return conventions, nil
+}
+
+// analyseConventions finds which conventions the schema's columns follow.
+// Conventions no column matches are left out.
+func analyseConventions(schema *Schema, conventions []NamingConvention) []ConventionUsage {
+	usages := []ConventionUsage{}
+	for _, convention := range conventions {
+		usage := ConventionUsage{
+			NamingConvention: convention,
+			Examples:         []string{},
+		}
+		for _, table := range schema.Tables {
+			for _, col := range table.allColumns() {
+				if matched, _ := path.Match(convention.Pattern, col.Name); !matched {
+					continue
+				}
+				usage.Count++
+				if len(usage.Examples) < maxConventionExamples {
+					usage.Examples = append(usage.Examples, table.Name+"."+col.Name)
+				}
+			}
+		}
+		if usage.Count > 0 {
+			usages = append(usages, usage)
+		}
+	}
+	return usages
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// csvDump writes the data dictionary to dir as flat CSV files, one per kind
+// of object, for spreadsheets and BI tools. Column names follow the -sqlite
+// catalog.
+func csvDump(schema *Schema, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	write := func(filename string, header []string, rows [][]string) error {
+		return withWriter(filepath.Join(dir, filename), func(w io.Writer) error {
+			out := csv.NewWriter(w)
+			if err := out.Write(header); err != nil {
+				return err
+			}
+			if err := out.WriteAll(rows); err != nil {
+				return err
+			}
+			return out.Error()
+		})
+	}
+
+	tables := [][]string{}
+	columns := [][]string{}
+	foreignKeys := [][]string{}
+	for _, table := range schema.Tables {
+		tables = append(tables, []string{
+			table.Name,
+			table.Description,
+			table.PrimaryKey,
+			strconv.Itoa(table.FanIn),
+			strconv.Itoa(table.FanOut),
+		})
+		for _, col := range table.allColumns() {
+			columns = append(columns, []string{
+				table.Name,
+				col.Name,
+				strconv.Itoa(col.Ordinal),
+				col.DataType,
+				strconv.FormatBool(col.CustomType),
+				strconv.FormatBool(col.IsNullable),
+				strconv.FormatBool(col.IsKey),
+				col.Description,
+				col.Generated,
+			})
+		}
+		// A row per column pair of composite keys
+		for _, fk := range table.ForeignKeys {
+			for colIdx, col := range fk.Columns {
+				foreignKeys = append(foreignKeys, []string{
+					table.Name,
+					fk.Name,
+					col,
+					fk.RefTable,
+					fk.RefColumns[colIdx],
+					strconv.FormatBool(fk.Logical),
+				})
+			}
+		}
+	}
+
+	enums := [][]string{}
+	for _, enum := range schema.Enums {
+		for idx, value := range enum.Values {
+			enums = append(enums, []string{
+				enum.Name,
+				strconv.Itoa(idx + 1),
+				value,
+				enum.Description,
+			})
+		}
+	}
+
+	functions := [][]string{}
+	for _, function := range schema.Functions {
+		functions = append(functions, []string{
+			function.Name,
+			function.Kind,
+			function.Arguments,
+			function.Returns,
+			function.Language,
+			function.Volatility,
+			function.Description,
+		})
+	}
+
+	if err := write("tables.csv", []string{"name", "description", "primary_key", "referenced_by", "references"}, tables); err != nil {
+		return err
+	}
+	if err := write("columns.csv", []string{"table_name", "name", "position", "data_type", "custom_type", "nullable", "is_key", "description", "generated"}, columns); err != nil {
+		return err
+	}
+	if err := write("foreign_keys.csv", []string{"table_name", "name", "column_name", "ref_table", "ref_column", "logical"}, foreignKeys); err != nil {
+		return err
+	}
+	if err := write("enums.csv", []string{"enum_name", "position", "value", "description"}, enums); err != nil {
+		return err
+	}
+	return write("functions.csv", []string{"name", "kind", "arguments", "returns", "language", "volatility", "description"}, functions)
+}
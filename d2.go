@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// d2PlainKey matches keys which can be written unquoted in D2
+var d2PlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// d2Dump renders the schema as a D2 diagram, a sql_table shape per table and
+// a connection per foreign key
+func d2Dump(schema *Schema, w io.Writer) error {
+	out := &strings.Builder{}
+
+	foreign := map[string]map[string]bool{}
+	for _, table := range schema.Tables {
+		foreign[table.Name] = map[string]bool{}
+		for _, fk := range table.ForeignKeys {
+			for _, col := range fk.Columns {
+				foreign[table.Name][col] = true
+			}
+		}
+	}
+
+	for _, table := range schema.Tables {
+		fmt.Fprintf(out, "%s: {\n", d2Key(table.Name))
+		fmt.Fprintln(out, "  shape: sql_table")
+		if table.Foreign != nil {
+			fmt.Fprintln(out, "  style.stroke-dash: 3")
+		}
+		if table.Description != "" {
+			fmt.Fprintf(out, "  tooltip: %s\n", d2String(table.Description))
+		}
+		for _, col := range table.allColumns() {
+			constraints := []string{}
+			if col.IsKey {
+				constraints = append(constraints, "primary_key")
+			}
+			if foreign[table.Name][col.Name] {
+				constraints = append(constraints, "foreign_key")
+			}
+			if col.Generated != "" {
+				constraints = append(constraints, "generated")
+			}
+			fmt.Fprintf(out, "  %s: %s", d2Key(col.Name), d2String(col.DataType))
+			switch len(constraints) {
+			case 0:
+			case 1:
+				fmt.Fprintf(out, " {constraint: %s}", constraints[0])
+			default:
+				fmt.Fprintf(out, " {constraint: [%s]}", strings.Join(constraints, "; "))
+			}
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+
+	for _, table := range schema.Tables {
+		for _, fk := range table.ForeignKeys {
+			// An edge per column pair, as d2 can't join several columns
+			for colIdx, col := range fk.Columns {
+				fmt.Fprintf(out, "%s.%s -> %s.%s", d2Key(table.Name), d2Key(col), d2Key(fk.RefTable), d2Key(fk.RefColumns[colIdx]))
+				styles := []string{}
+				if fk.Logical {
+					styles = append(styles, "style.stroke-dash: 3")
+				}
+				if fk.IsCrossSchema() {
+					styles = append(styles, "style.stroke: "+d2String(crossSchemaColor))
+				}
+				if len(styles) > 0 {
+					fmt.Fprintf(out, " {%s}", strings.Join(styles, "; "))
+				}
+				fmt.Fprintln(out)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func d2Key(name string) string {
+	if d2PlainKey.MatchString(name) {
+		return name
+	}
+	return d2String(name)
+}
+
+// d2String quotes a value. D2 strings can't span lines, so whitespace is
+// collapsed.
+func d2String(val string) string {
+	val = strings.Join(strings.Fields(val), " ")
+	return `"` + strings.ReplaceAll(val, `"`, `\"`) + `"`
+}
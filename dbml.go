@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// dbmlPlainName and dbmlPlainType match names and types which can be
+	// written unquoted in DBML
+	dbmlPlainName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	dbmlPlainType = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\([0-9, ]*\))?(\[\])?$`)
+)
+
+// dbmlDump renders the schema as DBML for dbdiagram.io and dbdocs
+func dbmlDump(schema *Schema, w io.Writer) error {
+	out := &strings.Builder{}
+
+	for _, table := range schema.Tables {
+		fmt.Fprintf(out, "Table %s {\n", dbmlName(table.Name))
+		for _, col := range table.allColumns() {
+			settings := []string{}
+			if col.IsKey {
+				settings = append(settings, "pk")
+			}
+			if !col.IsNullable {
+				settings = append(settings, "not null")
+			}
+			for _, check := range col.Checks {
+				settings = append(settings, "check: `"+checkExpression(check)+"`")
+			}
+			// DBML has no generated columns, so they're noted
+			note := col.Description
+			if col.Generated != "" {
+				note = strings.TrimSpace("GENERATED ALWAYS AS (" + col.Generated + ") STORED\n" + note)
+			}
+			if note != "" {
+				settings = append(settings, "note: "+dbmlString(note))
+			}
+			fmt.Fprintf(out, "  %s %s", dbmlName(col.Name), dbmlType(col.DataType))
+			if len(settings) > 0 {
+				fmt.Fprintf(out, " [%s]", strings.Join(settings, ", "))
+			}
+			fmt.Fprintln(out)
+		}
+		if table.Description != "" {
+			fmt.Fprintf(out, "\n  Note: %s\n", dbmlString(table.Description))
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+
+	for _, enum := range schema.Enums {
+		fmt.Fprintf(out, "Enum %s {\n", dbmlName(enum.Name))
+		for _, value := range enum.Values {
+			fmt.Fprintf(out, "  %s\n", dbmlName(value))
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+
+	for _, table := range schema.Tables {
+		for _, fk := range table.ForeignKeys {
+			fmt.Fprintf(out, "Ref %s: %s.%s > %s.%s\n",
+				dbmlName(fk.Name),
+				dbmlName(table.Name), dbmlColumns(fk.Columns),
+				dbmlName(fk.RefTable), dbmlColumns(fk.RefColumns))
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// dbmlName quotes identifiers which DBML wouldn't otherwise parse
+func dbmlName(name string) string {
+	if dbmlPlainName.MatchString(name) {
+		return name
+	}
+	return dbmlQuote(name)
+}
+
+func dbmlType(dataType string) string {
+	if dbmlPlainType.MatchString(dataType) {
+		return dataType
+	}
+	return dbmlQuote(dataType)
+}
+
+func dbmlQuote(val string) string {
+	return `"` + strings.ReplaceAll(val, `"`, `\"`) + `"`
+}
+
+// dbmlString quotes a note, using a multi-line string when it has breaks
+func dbmlString(val string) string {
+	if strings.Contains(val, "\n") {
+		return "'''" + strings.ReplaceAll(val, "'''", `\'''`) + "'''"
+	}
+	return "'" + strings.ReplaceAll(val, "'", `\'`) + "'"
+}
+
+// dbmlColumns is a column name, or the parenthesised list DBML uses for
+// composite references
+func dbmlColumns(columns []string) string {
+	names := []string{}
+	for _, col := range columns {
+		names = append(names, dbmlName(col))
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+type dbtProject struct {
+	Version int         `json:"version"`
+	Sources []dbtSource `json:"sources"`
+}
+
+type dbtSource struct {
+	Name   string     `json:"name"`
+	Schema string     `json:"schema"`
+	Tables []dbtTable `json:"tables"`
+}
+
+type dbtTable struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Columns     []dbtColumn `json:"columns"`
+}
+
+type dbtColumn struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Tests are test names or single key maps of a test to its arguments
+	Tests []interface{} `json:"tests,omitempty"`
+}
+
+// dbtDump writes a dbt schema.yml declaring the tables as a source named
+// source, with descriptions and tests implied by the schema: not_null,
+// unique for single column primary keys, accepted_values for enums and
+// relationships for foreign keys
+func dbtDump(schema *Schema, w io.Writer, source string) error {
+	enums := enumsByName(schema)
+	src := dbtSource{
+		Name:   source,
+		Schema: "public",
+		Tables: []dbtTable{},
+	}
+
+	for _, table := range schema.Tables {
+		refs := map[string]ForeignKeyDefinition{}
+		// dbt's relationships test only takes a single column
+		for _, fk := range table.ForeignKeys {
+			if !fk.Logical && !fk.IsComposite() {
+				refs[fk.Column] = fk
+			}
+		}
+
+		model := dbtTable{
+			Name:        table.Name,
+			Description: table.Description,
+			Columns:     []dbtColumn{},
+		}
+		for _, col := range table.allColumns() {
+			column := dbtColumn{
+				Name:        col.Name,
+				Description: col.Description,
+			}
+			if !col.IsNullable {
+				column.Tests = append(column.Tests, "not_null")
+			}
+			if col.IsKey && table.keyCount() == 1 {
+				column.Tests = append(column.Tests, "unique")
+			}
+			if enum, ok := enums[col.DataType]; ok {
+				column.Tests = append(column.Tests, map[string]interface{}{
+					"accepted_values": map[string]interface{}{"values": enum.Values},
+				})
+			}
+			if fk, ok := refs[col.Name]; ok {
+				column.Tests = append(column.Tests, map[string]interface{}{
+					"relationships": map[string]string{
+						"to":    fmt.Sprintf("source('%s', '%s')", source, fk.RefTable),
+						"field": fk.RefColumn,
+					},
+				})
+			}
+			model.Columns = append(model.Columns, column)
+		}
+		src.Tables = append(src.Tables, model)
+	}
+
+	return writeYAML(w, dbtProject{
+		Version: 2,
+		Sources: []dbtSource{src},
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// readDescriptions reads a YAML (or JSON) map of `table`, `table.column` or
+// enum names to descriptions
+func readDescriptions(filename string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := map[string]string{}
+	if err := yaml.Unmarshal(data, &descriptions); err != nil {
+		return nil, fmt.Errorf("Reading %s: %w", filename, err)
+	}
+	return descriptions, nil
+}
+
+// applyDescriptions replaces the database's comments with the given
+// descriptions, or with fillOnly only fills in missing ones. Descriptions
+// for objects which don't exist are warned about.
+func applyDescriptions(schema *Schema, descriptions map[string]string, fillOnly bool) {
+	used := map[string]bool{}
+	apply := func(key string, into *string) {
+		description, ok := descriptions[key]
+		if !ok {
+			return
+		}
+		used[key] = true
+		if fillOnly && *into != "" {
+			return
+		}
+		*into = description
+	}
+
+	for idx := range schema.Tables {
+		table := &schema.Tables[idx]
+		apply(table.Name, &table.Description)
+		for colIdx := range table.KeyColumns {
+			apply(table.Name+"."+table.KeyColumns[colIdx].Name, &table.KeyColumns[colIdx].Description)
+		}
+		for colIdx := range table.Columns {
+			apply(table.Name+"."+table.Columns[colIdx].Name, &table.Columns[colIdx].Description)
+		}
+	}
+	for idx := range schema.Enums {
+		apply(schema.Enums[idx].Name, &schema.Enums[idx].Description)
+	}
+
+	for key := range descriptions {
+		if used[key] {
+			continue
+		}
+		if strings.Contains(key, ".") {
+			warnf("description for %s: no such column", key)
+		} else {
+			warnf("description for %s: no such table or enum", key)
+		}
+	}
+}
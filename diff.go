@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	changeAdded   = "Added"
+	changeDropped = "Dropped"
+	changeChanged = "Changed"
+)
+
+// SchemaChange is one difference between two schemas
+type SchemaChange struct {
+	Change string `json:"change"`
+	Object string `json:"object"`
+	Name   string `json:"name"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+func (sc SchemaChange) String() string {
+	if sc.Change == changeChanged {
+		return fmt.Sprintf("%s %s %s from %s to %s", sc.Change, sc.Object, sc.Name, sc.From, sc.To)
+	}
+	return fmt.Sprintf("%s %s %s", sc.Change, sc.Object, sc.Name)
+}
+
+// diffSchemas lists what would need to change to turn from into to, in the
+// order the objects appear in the schemas.
+func diffSchemas(from, to *Schema) []SchemaChange {
+	changes := []SchemaChange{}
+
+	fromTables := map[string]Table{}
+	for _, table := range from.Tables {
+		fromTables[table.Name] = table
+	}
+	toTables := map[string]Table{}
+	for _, table := range to.Tables {
+		toTables[table.Name] = table
+	}
+
+	for _, table := range from.Tables {
+		if _, ok := toTables[table.Name]; !ok {
+			changes = append(changes, SchemaChange{Change: changeDropped, Object: "table", Name: table.Name})
+		}
+	}
+	for _, table := range to.Tables {
+		old, ok := fromTables[table.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{Change: changeAdded, Object: "table", Name: table.Name})
+			continue
+		}
+		changes = append(changes, diffTables(old, table)...)
+	}
+
+	fromEnums := map[string]Enum{}
+	for _, enum := range from.Enums {
+		fromEnums[enum.Name] = enum
+	}
+	toEnums := map[string]Enum{}
+	for _, enum := range to.Enums {
+		toEnums[enum.Name] = enum
+	}
+
+	for _, enum := range from.Enums {
+		if _, ok := toEnums[enum.Name]; !ok {
+			changes = append(changes, SchemaChange{Change: changeDropped, Object: "enum", Name: enum.Name})
+		}
+	}
+	for _, enum := range to.Enums {
+		old, ok := fromEnums[enum.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{Change: changeAdded, Object: "enum", Name: enum.Name})
+			continue
+		}
+		changes = append(changes, diffStrings("enum value", enum.Name+".", old.Values, enum.Values)...)
+	}
+
+	return changes
+}
+
+func diffTables(from, to Table) []SchemaChange {
+	changes := []SchemaChange{}
+
+	fromColumns := map[string]ColumnDefinition{}
+	for _, col := range from.allColumns() {
+		fromColumns[col.Name] = col
+	}
+	toColumns := map[string]ColumnDefinition{}
+	for _, col := range to.allColumns() {
+		toColumns[col.Name] = col
+	}
+
+	for _, col := range from.allColumns() {
+		if _, ok := toColumns[col.Name]; !ok {
+			changes = append(changes, SchemaChange{Change: changeDropped, Object: "column", Name: to.Name + "." + col.Name})
+		}
+	}
+	for _, col := range to.allColumns() {
+		name := to.Name + "." + col.Name
+		old, ok := fromColumns[col.Name]
+		if !ok {
+			changes = append(changes, SchemaChange{Change: changeAdded, Object: "column", Name: name})
+			continue
+		}
+		if old.DataType != col.DataType {
+			changes = append(changes, SchemaChange{Change: changeChanged, Object: "column", Name: name, From: old.DataType, To: col.DataType})
+		}
+		if old.IsNullable != col.IsNullable {
+			changes = append(changes, SchemaChange{Change: changeChanged, Object: "column", Name: name, From: nullability(old), To: nullability(col)})
+		}
+		if old.IsKey != col.IsKey {
+			change := changeAdded
+			if old.IsKey {
+				change = changeDropped
+			}
+			changes = append(changes, SchemaChange{Change: change, Object: "primary key column", Name: name})
+		}
+	}
+
+	fromFKs := make([]string, 0, len(from.ForeignKeys))
+	for _, fk := range from.ForeignKeys {
+		fromFKs = append(fromFKs, fk.Name)
+	}
+	toFKs := make([]string, 0, len(to.ForeignKeys))
+	for _, fk := range to.ForeignKeys {
+		toFKs = append(toFKs, fk.Name)
+	}
+	changes = append(changes, diffStrings("foreign key", to.Name+".", fromFKs, toFKs)...)
+
+	return changes
+}
+
+// diffStrings reports the added and dropped members of a list
+func diffStrings(object string, prefix string, from, to []string) []SchemaChange {
+	changes := []SchemaChange{}
+	inFrom := map[string]bool{}
+	for _, val := range from {
+		inFrom[val] = true
+	}
+	inTo := map[string]bool{}
+	for _, val := range to {
+		inTo[val] = true
+	}
+	for _, val := range from {
+		if !inTo[val] {
+			changes = append(changes, SchemaChange{Change: changeDropped, Object: object, Name: prefix + val})
+		}
+	}
+	for _, val := range to {
+		if !inFrom[val] {
+			changes = append(changes, SchemaChange{Change: changeAdded, Object: object, Name: prefix + val})
+		}
+	}
+	return changes
+}
+
+func nullability(col ColumnDefinition) string {
+	if col.IsNullable {
+		return "nullable"
+	}
+	return "not null"
+}
+
+// changelogDump writes the changes as markdown release notes
+func changelogDump(changes []SchemaChange, w io.Writer) error {
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "Schema Changes\n==============\n\n")
+	if len(changes) == 0 {
+		fmt.Fprintf(out, "No changes.\n")
+	}
+	for _, change := range changes {
+		fmt.Fprintf(out, "- %s\n", change.String())
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// mdxEscaper escapes the characters MDX would read as JSX or expressions
+var mdxEscaper = strings.NewReplacer(
+	"{", `\{`,
+	"}", `\}`,
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// docusaurusDump writes the schema to dir as a Docusaurus docs category, an
+// MDX page per table, one page for all the enums and one for the functions
+func docusaurusDump(schema *Schema, dir string, options MarkdownOptions) error {
+	tpl, err := markdownTemplate(options)
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(template.FuncMap{
+		"mdescape": func(val string) string {
+			val = mdxEscaper.Replace(val)
+			val = strings.ReplaceAll(val, "\n\n", "<br />")
+			val = strings.ReplaceAll(val, "\n", " ")
+			return val
+		},
+		"typeHref": func(dataType string) string {
+			return "enums.mdx#" + anchor(dataType)
+		},
+		"tableHref": func(name string) string {
+			return anchor(name) + ".mdx"
+		},
+	})
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := withWriter(filepath.Join(dir, "_category_.json"), func(w io.Writer) error {
+		bytes, err := json.MarshalIndent(map[string]interface{}{
+			"label": "Database Schema",
+			"link": map[string]string{
+				"type": "generated-index",
+			},
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// page writes a page's front matter, then its body between the header
+	// and footer
+	page := func(id string, title string, position int, body func(w io.Writer) error) error {
+		return withWriter(filepath.Join(dir, id+".mdx"), func(w io.Writer) error {
+			if err := docusaurusFrontMatter(w, id, title, position); err != nil {
+				return err
+			}
+			return options.decorated(body)(w)
+		})
+	}
+
+	for idx, table := range schema.Tables {
+		table.Description = mdxEscaper.Replace(table.Description)
+		if err := page(anchor(table.Name), table.Name, idx+1, func(w io.Writer) error {
+			return tpl.ExecuteTemplate(w, "table", table)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Domains and composite types share the enums page, as that's where
+	// typeHref points
+	if len(schema.Enums) > 0 || len(schema.Domains) > 0 || len(schema.Types) > 0 {
+		if err := page("enums", "Enums", len(schema.Tables)+1, func(w io.Writer) error {
+			for _, enum := range schema.Enums {
+				enum.Description = mdxEscaper.Replace(enum.Description)
+				if err := tpl.ExecuteTemplate(w, "enum", enum); err != nil {
+					return err
+				}
+			}
+			if len(schema.Domains) > 0 {
+				fmt.Fprintf(w, "\nDomains\n=======\n")
+			}
+			for _, domain := range schema.Domains {
+				domain.Description = mdxEscaper.Replace(domain.Description)
+				if err := tpl.ExecuteTemplate(w, "domain", domain); err != nil {
+					return err
+				}
+			}
+			if len(schema.Types) > 0 {
+				fmt.Fprintf(w, "\nTypes\n=====\n")
+			}
+			for _, compositeType := range schema.Types {
+				compositeType.Description = mdxEscaper.Replace(compositeType.Description)
+				if err := tpl.ExecuteTemplate(w, "composite", compositeType); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.Functions) == 0 {
+		return nil
+	}
+	return page("functions", "Functions", len(schema.Tables)+2, func(w io.Writer) error {
+		for _, function := range schema.Functions {
+			function.Description = mdxEscaper.Replace(function.Description)
+			if err := tpl.ExecuteTemplate(w, "function", function); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func docusaurusFrontMatter(w io.Writer, id string, title string, position int) error {
+	// JSON strings are valid YAML, and quote anything awkward in a title
+	quoted, err := json.Marshal(title)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "---\nid: %s\ntitle: %s\nsidebar_position: %d\n---\n", id, quoted, position)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Domain is a named base type with constraints. Columns of a domain keep
+// the base type as their DataType, and name the domain in Domain.
+type Domain struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	BaseType    string   `json:"baseType"`
+	NotNull     bool     `json:"notNull"`
+	Default     string   `json:"default,omitempty"`
+	Checks      []string `json:"checks,omitempty"`
+}
+
+// getDomains reads the domains in the schema with their comments
+func getDomains(ctx context.Context, db *sqrlx.Wrapper, schema string) ([]Domain, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT t.typname,
+	COALESCE(pg_catalog.obj_description(t.oid, 'pg_type'), ''),
+	pg_catalog.format_type(t.typbasetype, t.typtypmod),
+	t.typnotnull,
+	COALESCE(t.typdefault, ''),
+	ARRAY(
+		SELECT pg_catalog.pg_get_constraintdef(c.oid, true)
+		FROM pg_catalog.pg_constraint c
+		WHERE c.contypid = t.oid AND c.contype = 'c'
+		ORDER BY c.conname
+	)
+	FROM pg_catalog.pg_type t
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+	WHERE n.nspname = $1 AND t.typtype = 'd'
+	ORDER BY t.typname`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := []Domain{}
+	for rows.Next() {
+		domain := Domain{}
+		checks := pq.StringArray{}
+		if err := rows.Scan(&domain.Name, &domain.Description, &domain.BaseType, &domain.NotNull, &domain.Default, &checks); err != nil {
+			return nil, err
+		}
+		domain.Checks = []string(checks)
+		domains = append(domains, domain)
+	}
+	return domains, rows.Err()
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	drawioTableWidth = 220
+	drawioRowHeight  = 26
+	drawioGap        = 60
+)
+
+type drawioFile struct {
+	XMLName xml.Name      `xml:"mxfile"`
+	Host    string        `xml:"host,attr"`
+	Diagram drawioDiagram `xml:"diagram"`
+}
+
+type drawioDiagram struct {
+	ID    string       `xml:"id,attr"`
+	Name  string       `xml:"name,attr"`
+	Cells []drawioCell `xml:"mxGraphModel>root>mxCell"`
+}
+
+type drawioCell struct {
+	ID       string          `xml:"id,attr"`
+	Value    string          `xml:"value,attr,omitempty"`
+	Style    string          `xml:"style,attr,omitempty"`
+	Vertex   string          `xml:"vertex,attr,omitempty"`
+	Edge     string          `xml:"edge,attr,omitempty"`
+	Parent   string          `xml:"parent,attr,omitempty"`
+	Source   string          `xml:"source,attr,omitempty"`
+	Target   string          `xml:"target,attr,omitempty"`
+	Geometry *drawioGeometry `xml:"mxGeometry,omitempty"`
+}
+
+type drawioGeometry struct {
+	X        int    `xml:"x,attr,omitempty"`
+	Y        int    `xml:"y,attr,omitempty"`
+	Width    int    `xml:"width,attr,omitempty"`
+	Height   int    `xml:"height,attr,omitempty"`
+	Relative string `xml:"relative,attr,omitempty"`
+	As       string `xml:"as,attr"`
+}
+
+const (
+	drawioTableStyle = "swimlane;fontStyle=1;childLayout=stackLayout;horizontal=1;startSize=26;horizontalStack=0;resizeParent=1;resizeLast=0;collapsible=1;marginBottom=0;"
+	// drawioForeignStyle is added to foreign tables' style to dash them
+	drawioForeignStyle = "dashed=1;"
+	drawioColumnStyle  = "text;strokeColor=none;fillColor=none;align=left;verticalAlign=top;spacingLeft=4;spacingRight=4;overflow=hidden;rotatable=0;points=[[0,0.5],[1,0.5]];portConstraint=eastwest;"
+	drawioEdgeStyle    = "edgeStyle=entityRelationEdgeStyle;endArrow=ERmandOne;startArrow=ERmany;endFill=0;startFill=0;"
+)
+
+// drawioDump renders the schema as a diagrams.net (draw.io) file, a node per
+// table laid out in a grid and an edge per foreign key, ready to be arranged
+// by hand
+func drawioDump(schema *Schema, w io.Writer) error {
+	cells := []drawioCell{
+		{ID: "0"},
+		{ID: "1", Parent: "0"},
+	}
+
+	perRow := int(math.Ceil(math.Sqrt(float64(len(schema.Tables)))))
+	columnIDs := map[string]map[string]string{}
+	x, y, rowHeight := 0, 0, 0
+
+	for idx, table := range schema.Tables {
+		if idx > 0 && idx%perRow == 0 {
+			x = 0
+			y += rowHeight + drawioGap
+			rowHeight = 0
+		}
+
+		tableID := fmt.Sprintf("t%d", idx)
+		columns := table.allColumns()
+		height := drawioRowHeight * (len(columns) + 1)
+		if height > rowHeight {
+			rowHeight = height
+		}
+		style := drawioTableStyle
+		if table.Foreign != nil {
+			style += drawioForeignStyle
+		}
+		cells = append(cells, drawioCell{
+			ID:     tableID,
+			Value:  table.Name,
+			Style:  style,
+			Vertex: "1",
+			Parent: "1",
+			Geometry: &drawioGeometry{
+				X: x, Y: y, Width: drawioTableWidth, Height: height, As: "geometry",
+			},
+		})
+
+		columnIDs[table.Name] = map[string]string{}
+		for colIdx, col := range columns {
+			colID := fmt.Sprintf("%sc%d", tableID, colIdx)
+			columnIDs[table.Name][col.Name] = colID
+			value := col.Name + ": " + col.DataType
+			if col.IsKey {
+				value += " (PK)"
+			}
+			if col.Generated != "" {
+				value += " (generated)"
+			}
+			cells = append(cells, drawioCell{
+				ID:     colID,
+				Value:  value,
+				Style:  drawioColumnStyle,
+				Vertex: "1",
+				Parent: tableID,
+				Geometry: &drawioGeometry{
+					Y: drawioRowHeight * (colIdx + 1), Width: drawioTableWidth, Height: drawioRowHeight, As: "geometry",
+				},
+			})
+		}
+
+		x += drawioTableWidth + drawioGap
+	}
+
+	for idx, table := range schema.Tables {
+		for fkIdx, fk := range table.ForeignKeys {
+			style := drawioEdgeStyle
+			if fk.Logical {
+				style += "dashed=1;"
+			}
+			if fk.IsCrossSchema() {
+				style += "strokeColor=" + crossSchemaColor + ";"
+			}
+			// Composite keys get an edge per column pair
+			for colIdx, col := range fk.Columns {
+				target, ok := columnIDs[fk.RefTable][fk.RefColumns[colIdx]]
+				if !ok {
+					continue
+				}
+				id := fmt.Sprintf("t%df%d", idx, fkIdx)
+				if colIdx > 0 {
+					id += fmt.Sprintf("c%d", colIdx)
+				}
+				cells = append(cells, drawioCell{
+					ID:       id,
+					Value:    fk.Name,
+					Style:    style,
+					Edge:     "1",
+					Parent:   "1",
+					Source:   columnIDs[table.Name][col],
+					Target:   target,
+					Geometry: &drawioGeometry{Relative: "1", As: "geometry"},
+				})
+			}
+		}
+	}
+
+	file := drawioFile{
+		Host: "pgdoc",
+		Diagram: drawioDiagram{
+			ID:    "schema",
+			Name:  "Schema",
+			Cells: cells,
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
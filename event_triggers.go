@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// EventTrigger is a trigger on DDL events. Event triggers are per
+// database, so these aren't limited to the documented schema.
+type EventTrigger struct {
+	Name string `json:"name"`
+
+	// Event is ddl_command_start, ddl_command_end, sql_drop or
+	// table_rewrite
+	Event string `json:"event"`
+
+	// Tags limit the trigger to these commands, like CREATE TABLE
+	Tags []string `json:"tags,omitempty"`
+
+	// Function is schema qualified
+	Function string `json:"function"`
+
+	// Enabled is as for table triggers
+	Enabled string `json:"enabled"`
+}
+
+func getEventTriggers(ctx context.Context, db *sqrlx.Wrapper, report *Report) ([]EventTrigger, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT e.evtname, e.evtevent, COALESCE(e.evttags, '{}'),
+	n.nspname || '.' || p.proname, e.evtenabled::text
+	FROM pg_catalog.pg_event_trigger e
+	JOIN pg_catalog.pg_proc p ON p.oid = e.evtfoid
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+	ORDER BY e.evtname`)
+	if err := report.optional("event triggers", err); err != nil || rows == nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	triggers := []EventTrigger{}
+	for rows.Next() {
+		trigger := EventTrigger{}
+		var tags pq.StringArray
+		var enabled string
+		if err := rows.Scan(&trigger.Name, &trigger.Event, &tags, &trigger.Function, &enabled); err != nil {
+			return nil, err
+		}
+		trigger.Tags = tags
+		trigger.Enabled = triggerEnabled[enabled]
+		triggers = append(triggers, trigger)
+	}
+	return triggers, rows.Err()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// ExclusionConstraint is an EXCLUDE constraint, which no two rows may
+// satisfy together
+type ExclusionConstraint struct {
+	Name string `json:"name"`
+
+	// Method is the index access method, usually gist
+	Method string `json:"method"`
+
+	// Elements are each column or expression with its operator, as in
+	// during WITH &&
+	Elements []string `json:"elements"`
+
+	// Predicate is the WHERE clause of a partial constraint
+	Predicate string `json:"predicate,omitempty"`
+
+	Description string `json:"description,omitempty"`
+
+	Deferrable        bool `json:"deferrable,omitempty"`
+	InitiallyDeferred bool `json:"initiallyDeferred,omitempty"`
+}
+
+// Deferral is as for ForeignKeyDefinition
+func (e ExclusionConstraint) Deferral() string {
+	return deferral(e.Deferrable, e.InitiallyDeferred)
+}
+
+// addExclusionConstraints fills in each table's EXCLUDE constraints, which
+// information_schema leaves out
+func addExclusionConstraints(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table) error {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, con.conname, am.amname,
+	ARRAY(
+		SELECT pg_catalog.pg_get_indexdef(con.conindid, k.ord::int, true) || ' WITH ' || op.oprname
+		FROM unnest(con.conexclop) WITH ORDINALITY AS k(oid, ord)
+		JOIN pg_catalog.pg_operator op ON op.oid = k.oid
+		ORDER BY k.ord
+	),
+	COALESCE(pg_catalog.pg_get_expr(i.indpred, i.indrelid, true), ''),
+	con.condeferrable, con.condeferred
+	FROM pg_catalog.pg_constraint con
+	JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_index i ON i.indexrelid = con.conindid
+	JOIN pg_catalog.pg_class ic ON ic.oid = con.conindid
+	JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+	WHERE n.nspname = $1 AND con.contype = 'x'
+	ORDER BY c.relname, con.conname`, schema)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var tableName string
+		var elements pq.StringArray
+		exclusion := ExclusionConstraint{}
+		if err := rows.Scan(&tableName, &exclusion.Name, &exclusion.Method, &elements, &exclusion.Predicate, &exclusion.Deferrable, &exclusion.InitiallyDeferred); err != nil {
+			return err
+		}
+		exclusion.Elements = elements
+		if table, ok := byName[tableName]; ok {
+			table.ExclusionConstraints = append(table.ExclusionConstraints, exclusion)
+		}
+	}
+	return rows.Err()
+}
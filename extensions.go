@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Extension is an installed extension. Extensions are installed per
+// database, so these aren't limited to the documented schema.
+type Extension struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Schema      string `json:"schema"`
+	Description string `json:"description"`
+}
+
+func getExtensions(ctx context.Context, db *sqrlx.Wrapper) ([]Extension, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT e.extname, e.extversion, n.nspname,
+	COALESCE(pg_catalog.obj_description(e.oid, 'pg_extension'), '')
+	FROM pg_catalog.pg_extension e
+	JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+	ORDER BY e.extname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	extensions := []Extension{}
+	for rows.Next() {
+		extension := Extension{}
+		if err := rows.Scan(&extension.Name, &extension.Version, &extension.Schema, &extension.Description); err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, extension)
+	}
+	return extensions, rows.Err()
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexpChars only appear in names given to -include and -exclude which
+// are regular expressions
+const regexpChars = `^$+()|\{}`
+
+// NameFilter picks the tables and views to document by name. Each pattern
+// is a name, a glob like tmp_* or a regular expression like .*_archive$.
+type NameFilter struct {
+	include []func(string) bool
+	exclude []func(string) bool
+}
+
+func newNameFilter(include []string, exclude []string) (NameFilter, error) {
+	filter := NameFilter{}
+	for _, patterns := range []struct {
+		from []string
+		into *[]func(string) bool
+	}{
+		{include, &filter.include},
+		{exclude, &filter.exclude},
+	} {
+		for _, pattern := range patterns.from {
+			match, err := namePattern(pattern)
+			if err != nil {
+				return NameFilter{}, err
+			}
+			*patterns.into = append(*patterns.into, match)
+		}
+	}
+	return filter, nil
+}
+
+// namePattern tells what kind of pattern it is from the characters in it
+func namePattern(pattern string) (func(string) bool, error) {
+	switch {
+	case strings.ContainsAny(pattern, regexpChars) || strings.Contains(pattern, ".*"):
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	case strings.ContainsAny(pattern, "*?["):
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		return func(name string) bool {
+			matched, _ := path.Match(pattern, name)
+			return matched
+		}, nil
+	default:
+		return func(name string) bool {
+			return name == pattern
+		}, nil
+	}
+}
+
+// columnListed is true when the column is in a list of columns given by
+// name alone or as table.column, like -redact
+func columnListed(names []string, table string, column string) bool {
+	for _, name := range names {
+		if name == column || name == table+"."+column {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutColumns leaves out the columns of a table or view listed in
+// exclude
+func withoutColumns(cols []ColumnDefinition, exclude []string, table string) []ColumnDefinition {
+	if len(exclude) == 0 {
+		return cols
+	}
+	kept := []ColumnDefinition{}
+	for _, col := range cols {
+		if !columnListed(exclude, table, col.Name) {
+			kept = append(kept, col)
+		}
+	}
+	return kept
+}
+
+// excludeColumns leaves the columns listed in exclude out of the schema's
+// tables and views, along with the foreign keys, constraints and indexes
+// over them, which would otherwise still name them. Relations qualified with
+// their schema match by their plain name too.
+func excludeColumns(schema *Schema, exclude []string) {
+	if len(exclude) == 0 {
+		return
+	}
+	for idx := range schema.Tables {
+		table := &schema.Tables[idx]
+		columns := excludedColumns(exclude, table.Name)
+		table.KeyColumns = withoutNamedColumns(table.KeyColumns, columns)
+		table.Columns = withoutNamedColumns(table.Columns, columns)
+
+		fks := []ForeignKeyDefinition{}
+		for _, fk := range table.ForeignKeys {
+			if !namesAny(fk.Columns, columns) && !namesAny(fk.RefColumns, excludedColumns(exclude, fk.RefTable)) {
+				fks = append(fks, fk)
+			}
+		}
+		table.ForeignKeys = fks
+
+		uniques := []UniqueConstraint{}
+		for _, unique := range table.UniqueConstraints {
+			if !namesAny(unique.Columns, columns) {
+				uniques = append(uniques, unique)
+			}
+		}
+		table.UniqueConstraints = uniques
+
+		checks := []CheckConstraint{}
+		for _, check := range table.CheckConstraints {
+			if !namesAny(check.Columns, columns) && !mentionsAny(columns, check.Definition) {
+				checks = append(checks, check)
+			}
+		}
+		table.CheckConstraints = checks
+
+		exclusions := []ExclusionConstraint{}
+		for _, exclusion := range table.ExclusionConstraints {
+			if !mentionsAny(columns, append([]string{exclusion.Predicate}, exclusion.Elements...)...) {
+				exclusions = append(exclusions, exclusion)
+			}
+		}
+		table.ExclusionConstraints = exclusions
+
+		table.Indexes = withoutIndexesOn(table.Indexes, columns)
+
+		if table.Sample != nil {
+			kept := []int{}
+			sample := &SampleRows{Columns: []string{}, Rows: make([][]*string, len(table.Sample.Rows))}
+			for colIdx, col := range table.Sample.Columns {
+				if !columns[col] {
+					kept = append(kept, colIdx)
+					sample.Columns = append(sample.Columns, col)
+				}
+			}
+			for rowIdx, row := range table.Sample.Rows {
+				for _, colIdx := range kept {
+					if colIdx < len(row) {
+						sample.Rows[rowIdx] = append(sample.Rows[rowIdx], row[colIdx])
+					}
+				}
+			}
+			table.Sample = sample
+		}
+	}
+	for idx := range schema.Views {
+		view := &schema.Views[idx]
+		view.Columns = withoutNamedColumns(view.Columns, excludedColumns(exclude, view.Name))
+	}
+	for idx := range schema.MaterializedViews {
+		view := &schema.MaterializedViews[idx]
+		columns := excludedColumns(exclude, view.Name)
+		view.Columns = withoutNamedColumns(view.Columns, columns)
+		view.Indexes = withoutIndexesOn(view.Indexes, columns)
+	}
+}
+
+// excludedColumns are the names of the columns of a relation which exclude
+// lists
+func excludedColumns(exclude []string, relation string) map[string]bool {
+	relations := []string{relation}
+	if dot := strings.LastIndex(relation, "."); dot >= 0 {
+		relations = append(relations, relation[dot+1:])
+	}
+	columns := map[string]bool{}
+	for _, name := range exclude {
+		if !strings.Contains(name, ".") {
+			columns[name] = true
+			continue
+		}
+		for _, relation := range relations {
+			if column := strings.TrimPrefix(name, relation+"."); column != name {
+				columns[column] = true
+			}
+		}
+	}
+	return columns
+}
+
+func withoutNamedColumns(cols []ColumnDefinition, columns map[string]bool) []ColumnDefinition {
+	kept := []ColumnDefinition{}
+	for _, col := range cols {
+		if !columns[col.Name] {
+			kept = append(kept, col)
+		}
+	}
+	return kept
+}
+
+// withoutIndexesOn leaves out the indexes with a column, or an expression or
+// predicate mentioning one, in columns. A nil list, not read, stays nil.
+func withoutIndexesOn(indexes []Index, columns map[string]bool) []Index {
+	if indexes == nil {
+		return nil
+	}
+	kept := []Index{}
+	for _, index := range indexes {
+		if !mentionsAny(columns, append([]string{index.Predicate}, index.Columns...)...) {
+			kept = append(kept, index)
+		}
+	}
+	return kept
+}
+
+func namesAny(names []string, columns map[string]bool) bool {
+	for _, name := range names {
+		if columns[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsAny is true when any of the SQL fragments has one of the columns
+// as a word in it, quoted or not
+func mentionsAny(columns map[string]bool, fragments ...string) bool {
+	for column := range columns {
+		word := regexp.MustCompile(`(^|[^\w$])"?` + regexp.QuoteMeta(column) + `"?($|[^\w$])`)
+		for _, fragment := range fragments {
+			if word.MatchString(fragment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Includes is true for names matching an include pattern, or any name
+// when there are none, unless they match an exclude pattern
+func (f NameFilter) Includes(name string) bool {
+	included := len(f.include) == 0
+	for _, match := range f.include {
+		if match(name) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, match := range f.exclude {
+		if match(name) {
+			return false
+		}
+	}
+	return true
+}
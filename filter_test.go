@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExcludeColumns(t *testing.T) {
+	secret := "hunter2"
+	name := "Ann"
+	schema := &Schema{
+		Tables: []Table{{
+			Name:       "users",
+			KeyColumns: []ColumnDefinition{{Name: "id"}},
+			Columns:    []ColumnDefinition{{Name: "name"}, {Name: "password_hash"}},
+			ForeignKeys: []ForeignKeyDefinition{
+				{Name: "users_hash_fkey", RefTable: "hashes", Columns: []string{"password_hash"}, RefColumns: []string{"hash"}},
+				{Name: "users_org_fkey", RefTable: "public.orgs", Columns: []string{"org_id"}, RefColumns: []string{"secret_id"}},
+				{Name: "users_team_fkey", RefTable: "teams", Columns: []string{"team_id"}, RefColumns: []string{"id"}},
+			},
+			UniqueConstraints: []UniqueConstraint{{Name: "users_hash_key", Columns: []string{"password_hash"}}, {Name: "users_name_key", Columns: []string{"name"}}},
+			CheckConstraints: []CheckConstraint{
+				{Name: "users_hash_check", Definition: "CHECK (length(password_hash) = 60)", Columns: []string{"password_hash"}},
+				{Name: "users_old_check", Definition: `CHECK ("password_hash" <> name)`},
+				{Name: "users_name_check", Definition: "CHECK (name <> '')", Columns: []string{"name"}},
+			},
+			Indexes: []Index{
+				{Name: "users_hash_idx", Columns: []string{"lower(password_hash)"}},
+				{Name: "users_name_idx", Columns: []string{"name"}, Predicate: "password_hash IS NOT NULL"},
+				{Name: "users_name_hash_idx", Columns: []string{"name"}, Predicate: "password_hash_old IS NULL"},
+			},
+			Sample: &SampleRows{Columns: []string{"id", "password_hash", "name"}, Rows: [][]*string{{nil, &secret, &name}}},
+		}},
+		MaterializedViews: []MaterializedView{{
+			View:    View{Name: "logins", Columns: []ColumnDefinition{{Name: "password_hash"}, {Name: "at"}}},
+			Indexes: []Index{{Name: "logins_hash_idx", Columns: []string{"password_hash"}}},
+		}},
+	}
+
+	excludeColumns(schema, []string{"password_hash", "orgs.secret_id"})
+
+	table := schema.Tables[0]
+	names := func(count int, name func(int) string) []string {
+		names := []string{}
+		for idx := 0; idx < count; idx++ {
+			names = append(names, name(idx))
+		}
+		return names
+	}
+	for _, tc := range []struct {
+		what string
+		got  []string
+		want []string
+	}{
+		{"columns", names(len(table.Columns), func(idx int) string { return table.Columns[idx].Name }), []string{"name"}},
+		{"foreign keys", names(len(table.ForeignKeys), func(idx int) string { return table.ForeignKeys[idx].Name }), []string{"users_team_fkey"}},
+		{"unique constraints", names(len(table.UniqueConstraints), func(idx int) string { return table.UniqueConstraints[idx].Name }), []string{"users_name_key"}},
+		{"check constraints", names(len(table.CheckConstraints), func(idx int) string { return table.CheckConstraints[idx].Name }), []string{"users_name_check"}},
+		{"indexes", names(len(table.Indexes), func(idx int) string { return table.Indexes[idx].Name }), []string{"users_name_hash_idx"}},
+		{"sample columns", table.Sample.Columns, []string{"id", "name"}},
+		{"materialized view columns", names(len(schema.MaterializedViews[0].Columns), func(idx int) string { return schema.MaterializedViews[0].Columns[idx].Name }), []string{"at"}},
+		{"materialized view indexes", names(len(schema.MaterializedViews[0].Indexes), func(idx int) string { return schema.MaterializedViews[0].Indexes[idx].Name }), []string{}},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.what, tc.want, tc.got)
+		}
+	}
+	if row := table.Sample.Rows[0]; len(row) != 2 || row[1] != &name {
+		t.Errorf("expected the sample row to keep id and name, got %v", row)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// ForeignTable is where a foreign table's rows come from
+type ForeignTable struct {
+	Server  string `json:"server"`
+	Wrapper string `json:"wrapper"`
+
+	// Options are given to the wrapper, like the remote table_name
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// addForeignTables fills in the server and options of the foreign tables
+func addForeignTables(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, s.srvname, w.fdwname, COALESCE(ft.ftoptions, '{}')
+	FROM pg_catalog.pg_foreign_table ft
+	JOIN pg_catalog.pg_class c ON c.oid = ft.ftrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_foreign_server s ON s.oid = ft.ftserver
+	JOIN pg_catalog.pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+	WHERE n.nspname = $1`, schema)
+	if err := report.optional("foreign tables", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var name string
+		foreign := &ForeignTable{}
+		options := pq.StringArray{}
+		if err := rows.Scan(&name, &foreign.Server, &foreign.Wrapper, &options); err != nil {
+			return err
+		}
+		table, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for _, option := range options {
+			parts := strings.SplitN(option, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if foreign.Options == nil {
+				foreign.Options = map[string]string{}
+			}
+			foreign.Options[parts[0]] = parts[1]
+		}
+		table.Foreign = foreign
+	}
+	return rows.Err()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Function is a function or procedure defined in the schema
+type Function struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Kind is function, procedure, aggregate or window
+	Kind string `json:"kind"`
+
+	// Arguments is the argument list as it would be declared, including
+	// names, modes and defaults
+	Arguments string `json:"arguments"`
+
+	// Returns is empty for procedures
+	Returns    string `json:"returns,omitempty"`
+	Language   string `json:"language"`
+	Volatility string `json:"volatility"`
+}
+
+// Signature is the name with the argument list, which is what identifies an
+// overloaded function
+func (f Function) Signature() string {
+	return f.Name + "(" + f.Arguments + ")"
+}
+
+// getFunctions reads the functions and procedures in the schema, leaving out
+// those which belong to an extension
+func getFunctions(ctx context.Context, db *sqrlx.Wrapper, schema string) ([]Function, error) {
+	version, err := serverVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// prokind replaced proisagg and proiswindow in Postgres 11, along with
+	// procedures
+	kind := "CASE WHEN p.proisagg THEN 'a' WHEN p.proiswindow THEN 'w' ELSE 'f' END"
+	if version >= 110000 {
+		kind = "p.prokind::text"
+	}
+
+	rows, err := db.QueryRaw(ctx, `SELECT p.proname, `+kind+`,
+	pg_catalog.pg_get_function_arguments(p.oid),
+	COALESCE(pg_catalog.pg_get_function_result(p.oid), ''),
+	l.lanname, p.provolatile::text,
+	COALESCE(pg_catalog.obj_description(p.oid, 'pg_proc'), '')
+	FROM pg_catalog.pg_proc p
+	JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_catalog.pg_language l ON l.oid = p.prolang
+	WHERE n.nspname = $1
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend d
+		WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+	)
+	ORDER BY p.proname, pg_catalog.pg_get_function_arguments(p.oid)`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kinds := map[string]string{"f": "function", "p": "procedure", "a": "aggregate", "w": "window"}
+	volatilities := map[string]string{"i": "immutable", "s": "stable", "v": "volatile"}
+
+	functions := []Function{}
+	for rows.Next() {
+		function := Function{}
+		var kind, volatility string
+		if err := rows.Scan(&function.Name, &kind, &function.Arguments, &function.Returns, &function.Language, &volatility, &function.Description); err != nil {
+			return nil, err
+		}
+		function.Kind = kinds[kind]
+		function.Volatility = volatilities[volatility]
+		functions = append(functions, function)
+	}
+	return functions, rows.Err()
+}
@@ -5,5 +5,9 @@ go 1.14
 require (
 	github.com/elgris/sqrl v0.0.0-20190909141434-5a439265eeec
 	github.com/lib/pq v1.4.0
+	github.com/mattn/go-sqlite3 v1.14.0
+	github.com/microcosm-cc/bluemonday v1.0.4
+	github.com/yuin/goldmark v1.2.1
 	gopkg.daemonl.com/sqrlx v0.0.1
+	gopkg.in/yaml.v2 v2.3.0
 )
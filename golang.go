@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goInitialisms are words written in capitals in Go names
+var goInitialisms = map[string]bool{
+	"api": true, "db": true, "dns": true, "html": true, "http": true,
+	"id": true, "ip": true, "json": true, "sql": true, "ssl": true,
+	"uri": true, "url": true, "utc": true, "uuid": true, "xml": true,
+}
+
+// goTypes are the field types of each kind, kinds missing here are strings
+var goTypes = map[typeKind]string{
+	kindInteger:    "int32",
+	kindBigInteger: "int64",
+	kindFloat:      "float64",
+	kindBoolean:    "bool",
+	kindTimestamp:  "time.Time",
+	kindDate:       "time.Time",
+	kindJSON:       "json.RawMessage",
+	kindBytes:      "[]byte",
+	kindArray:      "[]string",
+}
+
+// goImports are the packages each field type needs
+var goImports = map[string]string{
+	"time.Time":       "time",
+	"json.RawMessage": "encoding/json",
+}
+
+// goDump renders the schema as Go source in the given package, a struct per
+// table with db and json tags and a string type with constants per enum.
+// Nullable columns are pointers.
+func goDump(schema *Schema, w io.Writer, pkg string) error {
+	enums := enumsByName(schema)
+	imports := map[string]bool{}
+	body := &strings.Builder{}
+
+	for _, table := range schema.Tables {
+		goComment(body, table.Description)
+		fmt.Fprintf(body, "type %s struct {\n", goName(table.Name))
+		for _, col := range table.allColumns() {
+			goType := goTypeName(col.DataType)
+			if _, ok := enums[col.DataType]; ok {
+				goType = goName(col.DataType)
+			}
+			if pkg, ok := goImports[goType]; ok {
+				imports[pkg] = true
+			}
+			// Slices are already nil when null
+			if col.IsNullable && !strings.HasPrefix(goType, "[]") && goType != "json.RawMessage" {
+				goType = "*" + goType
+			}
+			field := goName(col.Name)
+			goComment(body, col.Description)
+			fmt.Fprintf(body, "%s %s `db:%s json:%s`\n", field, goType, strconv.Quote(col.Name), strconv.Quote(col.Name))
+		}
+		fmt.Fprintf(body, "}\n\n")
+	}
+
+	for _, enum := range schema.Enums {
+		name := goName(enum.Name)
+		goComment(body, enum.Description)
+		fmt.Fprintf(body, "type %s string\n\nconst (\n", name)
+		for _, value := range enum.Values {
+			fmt.Fprintf(body, "%s%s %s = %s\n", name, goName(value), name, strconv.Quote(value))
+		}
+		fmt.Fprintf(body, ")\n\n")
+	}
+
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "// Generated by pgdoc from the database schema.\n\npackage %s\n\n", pkg)
+	packages := []string{}
+	for pkg := range imports {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	if len(packages) > 0 {
+		fmt.Fprintln(out, "import (")
+		for _, pkg := range packages {
+			fmt.Fprintf(out, "%s\n", strconv.Quote(pkg))
+		}
+		fmt.Fprintf(out, ")\n\n")
+	}
+	out.WriteString(body.String())
+
+	source, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return fmt.Errorf("formatting generated Go: %w", err)
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+// goName converts a postgres name to an exported Go name
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	out := &strings.Builder{}
+	for _, word := range words {
+		if goInitialisms[strings.ToLower(word)] {
+			out.WriteString(strings.ToUpper(word))
+		} else {
+			out.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	if out.Len() == 0 || out.String()[0] >= '0' && out.String()[0] <= '9' {
+		return "X" + out.String()
+	}
+	return out.String()
+}
+
+func goComment(out *strings.Builder, description string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintln(out, strings.TrimRight("// "+line, " "))
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Grant is a privilege a role holds on a table, or on some of its columns
+type Grant struct {
+	Grantee   string `json:"grantee"`
+	Privilege string `json:"privilege"`
+
+	// Grantable is true WITH GRANT OPTION
+	Grantable bool `json:"grantable"`
+
+	// Columns limits the grant to these columns, empty means the table
+	Columns []string `json:"columns,omitempty"`
+}
+
+// addGrants fills in the privileges on each table. information_schema only
+// shows grants where the grantor or grantee is a role the current user is a
+// member of.
+func addGrants(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	rows, err := db.QueryRaw(ctx, `SELECT table_name, grantee, privilege_type, is_grantable = 'YES'
+	FROM information_schema.role_table_grants
+	WHERE table_schema = $1
+	ORDER BY table_name, grantee, privilege_type`, schema)
+	if err := report.optional("table privileges", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Column privileges include those the table grants imply, which are
+	// left out
+	tableWide := map[string]bool{}
+	for rows.Next() {
+		var tableName string
+		grant := Grant{}
+		if err := rows.Scan(&tableName, &grant.Grantee, &grant.Privilege, &grant.Grantable); err != nil {
+			return err
+		}
+		tableWide[tableName+"."+grant.Grantee+"."+grant.Privilege] = true
+		if table, ok := byName[tableName]; ok {
+			table.Grants = append(table.Grants, grant)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	columnRows, err := db.QueryRaw(ctx, `SELECT table_name, column_name, grantee, privilege_type, is_grantable = 'YES'
+	FROM information_schema.role_column_grants
+	WHERE table_schema = $1
+	ORDER BY table_name, grantee, privilege_type, column_name`, schema)
+	if err := report.optional("column privileges", err); err != nil || columnRows == nil {
+		return err
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var tableName, columnName, grantee, privilege string
+		var grantable bool
+		if err := columnRows.Scan(&tableName, &columnName, &grantee, &privilege, &grantable); err != nil {
+			return err
+		}
+		table, ok := byName[tableName]
+		if !ok || tableWide[tableName+"."+grantee+"."+privilege] {
+			continue
+		}
+		// Rows are ordered, so a column of the same grant follows the last
+		if last := len(table.Grants) - 1; last >= 0 && len(table.Grants[last].Columns) > 0 &&
+			table.Grants[last].Grantee == grantee && table.Grants[last].Privilege == privilege && table.Grants[last].Grantable == grantable {
+			table.Grants[last].Columns = append(table.Grants[last].Columns, columnName)
+			continue
+		}
+		table.Grants = append(table.Grants, Grant{
+			Grantee:   grantee,
+			Privilege: privilege,
+			Grantable: grantable,
+			Columns:   []string{columnName},
+		})
+	}
+	return columnRows.Err()
+}
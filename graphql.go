@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// graphqlScalars are the custom scalars used for kinds GraphQL has no
+// built in type for
+var graphqlScalars = map[typeKind]string{
+	kindBigInteger: "BigInt",
+	kindDecimal:    "Decimal",
+	kindTimestamp:  "DateTime",
+	kindDate:       "Date",
+	kindTime:       "Time",
+	kindJSON:       "JSON",
+}
+
+// graphqlDump renders the schema as GraphQL SDL, a type per table and an
+// enum per enum. Foreign keys add a field holding the referenced row
+// alongside the key column itself.
+func graphqlDump(schema *Schema, w io.Writer) error {
+	enums := enumsByName(schema)
+	scalars := map[string]bool{}
+	body := &strings.Builder{}
+
+	fieldType := func(table Table, col ColumnDefinition) string {
+		var name string
+		if _, ok := enums[col.DataType]; ok {
+			name = graphqlName(col.DataType, true)
+		} else if col.IsKey && table.keyCount() == 1 {
+			name = "ID"
+		} else {
+			name = graphqlTypeName(col.DataType)
+			if _, ok := graphqlScalars[dataTypeKind(col.DataType)]; ok {
+				scalars[name] = true
+			}
+		}
+		if !col.IsNullable {
+			name += "!"
+		}
+		return name
+	}
+
+	for _, table := range schema.Tables {
+		graphqlDescription(body, "", table.Description)
+		fmt.Fprintf(body, "type %s {\n", graphqlName(table.Name, true))
+
+		columns := table.allColumns()
+		taken := map[string]bool{}
+		for _, col := range columns {
+			taken[graphqlName(col.Name, false)] = true
+		}
+
+		for _, col := range columns {
+			graphqlDescription(body, "  ", col.Description)
+			fmt.Fprintf(body, "  %s: %s\n", graphqlName(col.Name, false), fieldType(table, col))
+			for _, fk := range table.ForeignKeys {
+				if fk.Column != col.Name || fk.IsComposite() {
+					continue
+				}
+				field := graphqlName(strings.TrimSuffix(col.Name, "_id"), false)
+				if taken[field] {
+					field = graphqlName(col.Name+"_ref", false)
+				}
+				taken[field] = true
+				refType := graphqlName(fk.RefTable, true)
+				if !col.IsNullable {
+					refType += "!"
+				}
+				fmt.Fprintf(body, "  %s: %s\n", field, refType)
+			}
+		}
+		fmt.Fprintf(body, "}\n\n")
+	}
+
+	for _, enum := range schema.Enums {
+		graphqlDescription(body, "", enum.Description)
+		fmt.Fprintf(body, "enum %s {\n", graphqlName(enum.Name, true))
+		for _, value := range enum.Values {
+			fmt.Fprintf(body, "  %s\n", graphqlEnumValue(value))
+		}
+		fmt.Fprintf(body, "}\n\n")
+	}
+
+	out := &strings.Builder{}
+	names := []string{}
+	for name := range scalars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "scalar %s\n", name)
+	}
+	if len(names) > 0 {
+		fmt.Fprintln(out)
+	}
+	out.WriteString(strings.TrimSuffix(body.String(), "\n"))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// graphqlName converts a postgres name to a GraphQL one, PascalCase for
+// types and camelCase for fields. Names can't start with a digit.
+func graphqlName(name string, typeName bool) string {
+	name = camelCase(name, typeName)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// graphqlEnumValue converts an enum value to the SCREAMING_SNAKE_CASE
+// GraphQL expects
+func graphqlEnumValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(value))
+	if value == "" || value[0] >= '0' && value[0] <= '9' {
+		value = "_" + value
+	}
+	return value
+}
+
+func graphqlDescription(out *strings.Builder, indent string, description string) {
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(out, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(strings.ReplaceAll(description, `"""`, `\"""`), "\n") {
+		fmt.Fprintf(out, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(out, "%s\"\"\"\n", indent)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestGraphQLKeyUnderColumnOrder checks a single column key is an ID however
+// -column-order arranges the columns
+func TestGraphQLKeyUnderColumnOrder(t *testing.T) {
+	for _, order := range []string{columnOrderKeysFirst, columnOrderOrdinal, columnOrderKeysFirstThenOrdinal} {
+		t.Run(order, func(t *testing.T) {
+			schema := &Schema{
+				Tables: []Table{{
+					Name:       "users",
+					KeyColumns: []ColumnDefinition{{Name: "id", DataType: "integer", IsKey: true, Ordinal: 1}},
+					Columns:    []ColumnDefinition{{Name: "name", DataType: "text", Ordinal: 2}},
+				}},
+			}
+			orderColumns(schema, order)
+
+			out := &bytes.Buffer{}
+			if err := graphqlDump(schema, out); err != nil {
+				t.Fatal(err)
+			}
+			if want := "  id: ID!\n"; !strings.Contains(out.String(), want) {
+				t.Errorf("expected %q, got:\n%s", want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// htmlFuncs are shared by the single page and site HTML templates
+func htmlFuncs(options MarkdownOptions) template.FuncMap {
+	descriptions := newDescriptionRenderer(options.HTMLRawDescriptions)
+	return template.FuncMap{
+		"description":     descriptions.block,
+		"cellDescription": descriptions.inline,
+		"join":            strings.Join,
+		"linkable":        options.linkable,
+		"anchor":          anchor,
+		"snakeToTitle":    snakeToTitle,
+		"paragraphs": func(val string) []string {
+			paragraphs := []string{}
+			for _, paragraph := range strings.Split(val, "\n\n") {
+				if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+					paragraphs = append(paragraphs, paragraph)
+				}
+			}
+			return paragraphs
+		},
+	}
+}
+
+// htmlDump renders the schema as a standalone HTML page with a sidebar and
+// client side search
+func htmlDump(schema *Schema, w io.Writer, options MarkdownOptions) error {
+	tpl, err := template.New("schema.html").Funcs(htmlFuncs(options)).Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+	return tpl.Execute(w, schema)
+}
+
+var htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Schema</title>
+<style>
+body { margin: 0; font-family: sans-serif; display: flex; }
+nav { position: sticky; top: 0; height: 100vh; overflow-y: auto; width: 16em; flex-shrink: 0; padding: 1em; box-sizing: border-box; background: #f4f4f4; }
+nav ul { list-style: none; padding: 0; }
+nav input { width: 100%; box-sizing: border-box; }
+main { padding: 1em 2em; flex-grow: 1; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<nav>
+<input id="search" type="search" placeholder="Search">
+<h3>Tables</h3>
+<ul>
+{{- range .Tables }}
+<li data-name="{{ .Name }}"><a href="#{{ anchor .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- if .Enums }}
+<h3>Enums</h3>
+<ul>
+{{- range .Enums }}
+<li data-name="{{ .Name }}"><a href="#{{ anchor .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Domains }}
+<h3>Domains</h3>
+<ul>
+{{- range .Domains }}
+<li data-name="{{ .Name }}"><a href="#{{ anchor .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Types }}
+<h3>Types</h3>
+<ul>
+{{- range .Types }}
+<li data-name="{{ .Name }}"><a href="#{{ anchor .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Functions }}
+<h3>Functions</h3>
+<ul>
+{{- range $idx, $function := .Functions }}
+<li data-name="function-{{ $idx }}"><a href="#function-{{ $idx }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+</nav>
+<main>
+<h1>Tables</h1>
+{{ range .Tables }}
+<section data-name="{{ .Name }}">
+<h2 id="{{ anchor .Name }}">{{ snakeToTitle .Name }}</h2>
+{{- if or .FanIn .FanOut }}
+<p>Referenced by {{ .FanIn }}, references {{ .FanOut }}</p>
+{{- end }}
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+<table>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+{{- range .KeyColumns }}
+<tr><td>{{ .Name }} (KEY)</td><td>{{ template "type" . }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+{{- range .Columns }}
+<tr><td>{{ .Name }}{{ if .IsKey }} (KEY){{ end }}{{ if .Generated }} (GENERATED){{ end }}</td><td>{{ template "type" . }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+</table>
+{{- if or .ForeignKeys .Polymorphic }}
+<ul>
+{{- range .ForeignKeys }}
+<li>{{ .Name }}{{ if .Logical }} (logical){{ end }}: {{ .ColumnList }} &rarr; <a href="#{{ anchor .RefTable }}">{{ .RefTable }}</a>.{{ .RefColumnList }}</li>
+{{- end }}
+{{- range .Polymorphic }}
+<li>{{ .Name }} (polymorphic on {{ .TypeColumn }}, {{ .IDColumn }}{{ if .Targets }}: {{ join .Targets ", " }}{{ end }})</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .RLSEnabled }}
+<h3>Security Policies</h3>
+<p>Row level security is enabled{{ if .RLSForced }} and forced for the table owner{{ end }}.</p>
+{{- if .DeniesAll }}
+<p><strong>Warning:</strong> there are no policies, so every row is hidden.</p>
+{{- else }}
+<table>
+<tr><th>Policy</th><th>Command</th><th>Roles</th><th>Type</th><th>Using</th><th>With Check</th></tr>
+{{- range .Policies }}
+<tr><td>{{ .Name }}</td><td>{{ .Command }}</td><td>{{ join .Roles ", " }}</td><td>{{ if .Permissive }}permissive{{ else }}restrictive{{ end }}</td><td><code>{{ .Using }}</code></td><td><code>{{ .WithCheck }}</code></td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+{{- with .Sample }}
+<details>
+<summary>Sample rows</summary>
+<table>
+<tr>{{ range .Columns }}<th>{{ . }}</th>{{ end }}</tr>
+{{- range .Formatted }}
+<tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+</details>
+{{- end }}
+</section>
+{{ end }}
+{{- if .Enums }}
+<h1>Enums</h1>
+{{ range .Enums }}
+<section data-name="{{ .Name }}">
+<h2 id="{{ anchor .Name }}">{{ snakeToTitle .Name }}</h2>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+{{- if .Transitions }}
+<table>
+<tr><th>From</th><th>To</th></tr>
+{{- range .Transitions }}
+<tr><td>{{ .From }}</td><td>{{ .To }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<ul>
+{{- range .Values }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+</section>
+{{ end }}
+{{- end }}
+{{- if .Domains }}
+<h1>Domains</h1>
+{{ range .Domains }}
+<section data-name="{{ .Name }}">
+<h2 id="{{ anchor .Name }}">{{ snakeToTitle .Name }}</h2>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+<ul>
+<li>Base type: <code>{{ .BaseType }}</code>{{ if .NotNull }}, not null{{ end }}</li>
+{{- if .Default }}
+<li>Default: <code>{{ .Default }}</code></li>
+{{- end }}
+{{- range .Checks }}
+<li><code>{{ . }}</code></li>
+{{- end }}
+</ul>
+</section>
+{{ end }}
+{{- end }}
+{{- if .Types }}
+<h1>Types</h1>
+{{ range .Types }}
+<section data-name="{{ .Name }}">
+<h2 id="{{ anchor .Name }}">{{ snakeToTitle .Name }}</h2>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+<table>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+{{- range .Attributes }}
+<tr><td>{{ .Name }}</td><td>{{ template "type" . }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+</table>
+</section>
+{{ end }}
+{{- end }}
+{{- if .Functions }}
+<h1>Functions</h1>
+{{ range $idx, $function := .Functions }}
+{{- /* Overloads share a name, so they're identified by position */}}
+<section data-name="function-{{ $idx }}">
+<h2 id="function-{{ $idx }}">{{ .Name }}</h2>
+<p><code>{{ .Signature }}</code>{{ if .Returns }} returns <code>{{ .Returns }}</code>{{ end }}</p>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+<ul>
+<li>Kind: {{ .Kind }}</li>
+<li>Language: {{ .Language }}</li>
+<li>Volatility: {{ .Volatility }}</li>
+</ul>
+</section>
+{{ end }}
+{{- end }}
+</main>
+<script>
+document.getElementById("search").addEventListener("input", function (e) {
+  var query = e.target.value.toLowerCase();
+  document.querySelectorAll("section[data-name]").forEach(function (section) {
+    var match = section.textContent.toLowerCase().indexOf(query) >= 0;
+    section.classList.toggle("hidden", !match);
+    var item = document.querySelector("nav li[data-name='" + section.dataset.name + "']");
+    if (item) {
+      item.classList.toggle("hidden", !match);
+    }
+  });
+});
+</script>
+</body>
+</html>
+{{ define "type" }}
+{{- if .Domain }}<a href="#{{ anchor .Domain }}">{{ .Domain }}</a>{{ else if linkable . }}<a href="#{{ anchor .ElementType }}">{{ .DataType }}</a>{{ else }}{{ .DataType }}{{ end }}
+{{- with .Collation }} (collation {{ . }}){{ end }}
+{{- end -}}
+`
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// descriptionRenderer renders descriptions, which are often written in
+// markdown, as HTML. Comments can be set by anyone who can alter the
+// database, so unless raw the HTML is sanitized to the markup a user may
+// post on a forum: no scripts, styles, event handlers or javascript: links.
+type descriptionRenderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+func newDescriptionRenderer(raw bool) *descriptionRenderer {
+	renderer := &descriptionRenderer{
+		// HTML in the comments is passed through, for the policy to decide
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+	}
+	if !raw {
+		renderer.policy = bluemonday.UGCPolicy()
+	}
+	return renderer
+}
+
+// block renders a description as paragraphs, lists, code blocks and so on
+func (r *descriptionRenderer) block(description string) (template.HTML, error) {
+	buf := &bytes.Buffer{}
+	if err := r.markdown.Convert([]byte(description), buf); err != nil {
+		return "", err
+	}
+	if r.policy == nil {
+		return template.HTML(buf.String()), nil
+	}
+	return template.HTML(r.policy.SanitizeBytes(buf.Bytes())), nil
+}
+
+// inline renders a description for a table cell, without the paragraph
+// around one which is just a line or two
+func (r *descriptionRenderer) inline(description string) (template.HTML, error) {
+	rendered, err := r.block(description)
+	if err != nil {
+		return "", err
+	}
+	trimmed := strings.TrimSpace(string(rendered))
+	inner := strings.TrimSuffix(strings.TrimPrefix(trimmed, "<p>"), "</p>")
+	if len(inner) == len(trimmed)-len("<p></p>") && !strings.Contains(inner, "<p>") {
+		return template.HTML(inner), nil
+	}
+	return template.HTML(trimmed), nil
+}
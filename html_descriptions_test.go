@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDescriptionRenderer(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		raw         bool
+		description string
+		want        string
+	}{{
+		name:        "plain text",
+		description: "Customers & suppliers",
+		want:        "Customers &amp; suppliers",
+	}, {
+		name:        "markdown",
+		description: "Either:\n\n- `active`\n- [archived](https://example.com)",
+		want:        "<p>Either:</p>\n<ul>\n<li><code>active</code></li>\n<li><a href=\"https://example.com\" rel=\"nofollow\">archived</a></li>\n</ul>",
+	}, {
+		name:        "script",
+		description: "Hello <script>alert(1)</script>",
+		want:        "Hello ",
+	}, {
+		name:        "event handler",
+		description: `<img src="x.png" onerror="alert(1)">`,
+		want:        `<img src="x.png">`,
+	}, {
+		name:        "javascript link",
+		description: "[click](javascript:alert(1))",
+		want:        "click",
+	}, {
+		name:        "raw",
+		raw:         true,
+		description: `Hello <span onclick="go()">there</span>`,
+		want:        `Hello <span onclick="go()">there</span>`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newDescriptionRenderer(tc.raw).inline(tc.description)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
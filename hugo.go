@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// hugoDump writes the schema to dir as a Hugo content section: an _index.md,
+// a page per table, a page for the enums and one for the functions
+func hugoDump(schema *Schema, dir string, options MarkdownOptions) error {
+	tpl, err := markdownTemplate(options)
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(template.FuncMap{
+		// Hugo doesn't render raw HTML by default, so no <br>
+		"mdescape": func(val string) string {
+			return strings.Join(strings.Fields(val), " ")
+		},
+		"typeHref": func(dataType string) string {
+			return fmt.Sprintf(`{{< relref "enums.md#%s" >}}`, anchor(dataType))
+		},
+		"tableHref": func(name string) string {
+			return fmt.Sprintf(`{{< relref "%s.md" >}}`, anchor(name))
+		},
+	})
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := withWriter(filepath.Join(dir, "_index.md"), func(w io.Writer) error {
+		return hugoFrontMatter(w, "Database Schema", "", 0)
+	}); err != nil {
+		return err
+	}
+
+	// page writes a page's front matter, then its body between the header
+	// and footer
+	page := func(filename string, title string, description string, weight int, body func(w io.Writer) error) error {
+		return withWriter(filepath.Join(dir, filename), func(w io.Writer) error {
+			if err := hugoFrontMatter(w, title, description, weight); err != nil {
+				return err
+			}
+			return options.decorated(body)(w)
+		})
+	}
+
+	for idx, table := range schema.Tables {
+		summary := strings.SplitN(table.Description, "\n", 2)[0]
+		if err := page(anchor(table.Name)+".md", table.Name, summary, idx+1, func(w io.Writer) error {
+			return tpl.ExecuteTemplate(w, "table", table)
+		}); err != nil {
+			return err
+		}
+	}
+
+	// relref fails the build if the target is missing, so always write it
+	if err := page("enums.md", "Enums", "", len(schema.Tables)+1, func(w io.Writer) error {
+		for _, enum := range schema.Enums {
+			if err := tpl.ExecuteTemplate(w, "enum", enum); err != nil {
+				return err
+			}
+		}
+		// Domains and composite types share the page, as that's where
+		// typeHref points
+		if len(schema.Domains) > 0 {
+			fmt.Fprintf(w, "\nDomains\n=======\n")
+		}
+		for _, domain := range schema.Domains {
+			if err := tpl.ExecuteTemplate(w, "domain", domain); err != nil {
+				return err
+			}
+		}
+		if len(schema.Types) > 0 {
+			fmt.Fprintf(w, "\nTypes\n=====\n")
+		}
+		for _, compositeType := range schema.Types {
+			if err := tpl.ExecuteTemplate(w, "composite", compositeType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(schema.Functions) == 0 {
+		return nil
+	}
+	return page("functions.md", "Functions", "", len(schema.Tables)+2, func(w io.Writer) error {
+		for _, function := range schema.Functions {
+			if err := tpl.ExecuteTemplate(w, "function", function); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func hugoFrontMatter(w io.Writer, title string, description string, weight int) error {
+	// JSON strings are valid YAML, and quote anything awkward
+	quoted := func(val string) string {
+		bytes, _ := json.Marshal(val)
+		return string(bytes)
+	}
+	fmt.Fprintf(w, "---\ntitle: %s\n", quoted(title))
+	if description != "" {
+		fmt.Fprintf(w, "description: %s\n", quoted(description))
+	}
+	if weight > 0 {
+		fmt.Fprintf(w, "weight: %d\n", weight)
+	}
+	_, err := fmt.Fprintln(w, "---")
+	return err
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Index is an index on a table or materialized view
+type Index struct {
+	Name string `json:"name"`
+
+	// Columns are column names, or the expression for expression indexes
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+
+	// Method is the access method, btree, gin, gist etc.
+	Method string `json:"method"`
+
+	// Predicate is the WHERE clause of a partial index
+	Predicate string `json:"predicate,omitempty"`
+
+	Description string `json:"description,omitempty"`
+}
+
+// getIndexes reads the indexes on a relation
+func getIndexes(ctx context.Context, db *sqrlx.Wrapper, schema string, relName string) ([]Index, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT i.relname, ix.indisunique, ix.indisprimary, am.amname,
+	COALESCE(pg_catalog.pg_get_expr(ix.indpred, ix.indrelid, true), ''),
+	ARRAY(
+		SELECT pg_catalog.pg_get_indexdef(ix.indexrelid, k, true)
+		FROM generate_series(1, ix.indnatts) AS k
+		ORDER BY k
+	)
+	FROM pg_catalog.pg_index ix
+	JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+	JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_catalog.pg_am am ON am.oid = i.relam
+	WHERE n.nspname = $1 AND t.relname = $2
+	ORDER BY i.relname`, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := []Index{}
+	for rows.Next() {
+		index := Index{}
+		columns := pq.StringArray{}
+		if err := rows.Scan(&index.Name, &index.Unique, &index.Primary, &index.Method, &index.Predicate, &columns); err != nil {
+			return nil, err
+		}
+		index.Columns = []string(columns)
+		indexes = append(indexes, index)
+	}
+	return indexes, rows.Err()
+}
+
+// analyseIndexes warns of the indexes of a table or materialized view which
+// duplicate another, or are made redundant by one which starts with the same
+// columns. Only indexes with the same method and predicate are compared, so
+// partial indexes over different rows are left be, and unique indexes are
+// never redundant, as they enforce a constraint.
+func (r *Report) analyseIndexes(relName string, indexes []Index) {
+	for idx, index := range indexes {
+		for otherIdx, other := range indexes {
+			if idx == otherIdx || index.Method != other.Method || index.Predicate != other.Predicate {
+				continue
+			}
+			sameColumns := len(index.Columns) == len(other.Columns) && columnsPrefix(index.Columns, other.Columns)
+			if sameColumns && index.Unique == other.Unique {
+				// Each pair is reported once, keeping the primary key
+				if other.Primary || (!index.Primary && otherIdx < idx) {
+					r.warn(warnDuplicateIndex, index.Name, "duplicates %s on %s", other.Name, relName)
+					break
+				}
+			} else if !index.Unique && (sameColumns || index.Method == "btree" && columnsPrefix(index.Columns, other.Columns)) {
+				r.warn(warnRedundantIndex, index.Name, "is covered by %s on %s, which starts with the same columns", other.Name, relName)
+				break
+			}
+		}
+	}
+}
+
+// columnsPrefix is true when prefix are the leading columns of columns
+func columnsPrefix(prefix []string, columns []string) bool {
+	if len(prefix) > len(columns) {
+		return false
+	}
+	for idx, col := range prefix {
+		if columns[idx] != col {
+			return false
+		}
+	}
+	return true
+}
+
+// analyseForeignKeyIndexes warns of the foreign keys of a table whose
+// columns no index starts with, in any order, so every delete or key update
+// of the referenced table scans this one to check it. Partial indexes don't
+// cover every row, and logical keys aren't checked by postgres at all. A
+// table with no Indexes recorded, from a snapshot older than them, is left
+// be.
+func (r *Report) analyseForeignKeyIndexes(table Table) {
+	if table.Indexes == nil {
+		return
+	}
+	for _, fk := range table.ForeignKeys {
+		if fk.Logical || len(fk.Columns) == 0 {
+			continue
+		}
+		indexed := false
+		for _, index := range table.Indexes {
+			if index.Predicate == "" && columnsLead(fk.Columns, index.Columns) {
+				indexed = true
+				break
+			}
+		}
+		if !indexed {
+			r.warn(warnUnindexedForeignKey, fk.Name, "no index on %s starts with (%s), so changes to %s scan it", table.Name, strings.Join(fk.Columns, ", "), fk.RefTable)
+		}
+	}
+}
+
+// columnsLead is true when columns starts with those of lead, in any order
+func columnsLead(lead []string, columns []string) bool {
+	if len(lead) > len(columns) {
+		return false
+	}
+	leading := map[string]bool{}
+	for _, col := range columns[:len(lead)] {
+		leading[col] = true
+	}
+	for _, col := range lead {
+		if !leading[col] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyseIndexes(t *testing.T) {
+	btree := func(name string, unique bool, columns ...string) Index {
+		return Index{Name: name, Method: "btree", Unique: unique, Columns: columns}
+	}
+	pkey := btree("users_pkey", true, "id")
+	pkey.Primary = true
+	partial := btree("users_active_org_idx", false, "org_id")
+	partial.Predicate = "active"
+
+	for _, tc := range []struct {
+		name    string
+		indexes []Index
+		want    []string
+	}{{
+		name:    "distinct",
+		indexes: []Index{pkey, btree("users_org_idx", false, "org_id")},
+	}, {
+		name:    "exact duplicate",
+		indexes: []Index{btree("users_org_idx", false, "org_id"), btree("users_org_idx2", false, "org_id")},
+		want:    []string{"duplicate-index users_org_idx2"},
+	}, {
+		name:    "duplicate of the primary key",
+		indexes: []Index{btree("users_id_key", true, "id"), pkey},
+		want:    []string{"duplicate-index users_id_key"},
+	}, {
+		name:    "leading columns",
+		indexes: []Index{btree("users_org_idx", false, "org_id"), btree("users_org_name_idx", false, "org_id", "name")},
+		want:    []string{"redundant-index users_org_idx"},
+	}, {
+		name:    "not the leading columns",
+		indexes: []Index{btree("users_name_idx", false, "name"), btree("users_org_name_idx", false, "org_id", "name")},
+	}, {
+		name:    "covered by a unique index",
+		indexes: []Index{btree("users_email_idx", false, "email"), btree("users_email_key", true, "email")},
+		want:    []string{"redundant-index users_email_idx"},
+	}, {
+		name:    "unique prefix",
+		indexes: []Index{btree("users_org_key", true, "org_id"), btree("users_org_name_idx", false, "org_id", "name")},
+	}, {
+		name:    "partial with a different predicate",
+		indexes: []Index{partial, btree("users_org_idx", false, "org_id")},
+	}, {
+		name:    "different method",
+		indexes: []Index{{Name: "users_tags_idx", Method: "gin", Columns: []string{"tags"}}, btree("users_tags_btree", false, "tags")},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := newReport()
+			report.analyseIndexes("users", tc.indexes)
+			got := []string{}
+			for _, warning := range report.Warnings {
+				got = append(got, warning.Kind+" "+warning.Object)
+			}
+			if len(tc.want) == 0 {
+				tc.want = []string{}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyseForeignKeyIndexes(t *testing.T) {
+	fk := ForeignKeyDefinition{Name: "members_org_fkey", RefTable: "orgs", Columns: []string{"org_id", "region"}}
+	logical := fk
+	logical.Logical = true
+	index := func(predicate string, columns ...string) Index {
+		return Index{Name: "members_idx", Method: "btree", Columns: columns, Predicate: predicate}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		fk      ForeignKeyDefinition
+		indexes []Index
+		want    bool
+	}{{
+		name:    "no indexes",
+		fk:      fk,
+		indexes: []Index{},
+		want:    true,
+	}, {
+		name:    "indexes not recorded",
+		fk:      fk,
+		indexes: nil,
+	}, {
+		name:    "same columns",
+		fk:      fk,
+		indexes: []Index{index("", "org_id", "region")},
+	}, {
+		name:    "leading columns in another order",
+		fk:      fk,
+		indexes: []Index{index("", "region", "org_id", "name")},
+	}, {
+		name:    "only some of the columns",
+		fk:      fk,
+		indexes: []Index{index("", "org_id")},
+		want:    true,
+	}, {
+		name:    "not leading",
+		fk:      fk,
+		indexes: []Index{index("", "name", "org_id", "region")},
+		want:    true,
+	}, {
+		name:    "partial",
+		fk:      fk,
+		indexes: []Index{index("active", "org_id", "region")},
+		want:    true,
+	}, {
+		name:    "logical",
+		fk:      logical,
+		indexes: []Index{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := newReport()
+			report.analyseForeignKeyIndexes(Table{Name: "members", ForeignKeys: []ForeignKeyDefinition{tc.fk}, Indexes: tc.indexes})
+			if got := len(report.Warnings) == 1 && report.Warnings[0].Kind == warnUnindexedForeignKey; got != tc.want || len(report.Warnings) > 1 {
+				t.Errorf("expected a warning %v, got %v", tc.want, report.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect written by -json-schema
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+type jsonSchemaDocument struct {
+	Schema               string                        `json:"$schema"`
+	Title                string                        `json:"title"`
+	Description          string                        `json:"description,omitempty"`
+	Type                 string                        `json:"type"`
+	Properties           map[string]jsonSchemaProperty `json:"properties"`
+	Required             []string                      `json:"required"`
+	AdditionalProperties bool                          `json:"additionalProperties"`
+}
+
+type jsonSchemaProperty struct {
+	Ref   string               `json:"$ref,omitempty"`
+	AnyOf []jsonSchemaProperty `json:"anyOf,omitempty"`
+
+	// Type is a string, or a list when the column is nullable
+	Type        interface{}         `json:"type,omitempty"`
+	Format      string              `json:"format,omitempty"`
+	Enum        []interface{}       `json:"enum,omitempty"`
+	Items       *jsonSchemaProperty `json:"items,omitempty"`
+	Description string              `json:"description,omitempty"`
+
+	// ReadOnly marks generated columns
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// jsonSchemaTypes are the JSON type and format for each kind, kinds missing
+// here are plain strings
+var jsonSchemaTypes = map[typeKind][2]string{
+	kindInteger:    {"integer", ""},
+	kindBigInteger: {"integer", ""},
+	kindFloat:      {"number", ""},
+	kindDecimal:    {"number", ""},
+	kindBoolean:    {"boolean", ""},
+	kindTimestamp:  {"string", "date-time"},
+	kindDate:       {"string", "date"},
+	kindTime:       {"string", "time"},
+	kindUUID:       {"string", "uuid"},
+	kindArray:      {"array", ""},
+}
+
+// jsonSchemaDump writes a JSON Schema document per table to dir, named
+// after the table, describing a row as a JSON object
+func jsonSchemaDump(schema *Schema, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	enums := enumsByName(schema)
+	for _, table := range schema.Tables {
+		doc := jsonSchemaDocument{
+			Schema:      jsonSchemaDraft,
+			Title:       table.Name,
+			Description: table.Description,
+			Type:        "object",
+			Properties:  map[string]jsonSchemaProperty{},
+			Required:    []string{},
+		}
+		for _, col := range table.allColumns() {
+			doc.Properties[col.Name] = jsonSchemaColumn(col, enums)
+			if !col.IsNullable {
+				doc.Required = append(doc.Required, col.Name)
+			}
+		}
+
+		if err := withWriter(filepath.Join(dir, table.Name+".schema.json"), func(w io.Writer) error {
+			bytes, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(bytes)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonSchemaColumn(col ColumnDefinition, enums map[string]Enum) jsonSchemaProperty {
+	prop := jsonSchemaProperty{
+		Description: col.Description,
+		ReadOnly:    col.Generated != "",
+	}
+
+	if enum, ok := enums[col.DataType]; ok {
+		for _, value := range enum.Values {
+			prop.Enum = append(prop.Enum, value)
+		}
+		if col.IsNullable {
+			prop.Enum = append(prop.Enum, nil)
+		}
+		return prop
+	}
+
+	kind := dataTypeKind(col.DataType)
+	if kind == kindJSON {
+		// Any JSON value is valid, null included
+		return prop
+	}
+
+	jsonType, format := "string", ""
+	if types, ok := jsonSchemaTypes[kind]; ok {
+		jsonType, format = types[0], types[1]
+	}
+	prop.Type = jsonType
+	if col.IsNullable {
+		prop.Type = []string{jsonType, "null"}
+	}
+	prop.Format = format
+	if kind == kindArray {
+		prop.Items = &jsonSchemaProperty{}
+	}
+	return prop
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// latexDump renders the schema as a LaTeX document for printed or PDF data
+// dictionaries, a section with a longtable per table and an appendix of
+// enums
+func latexDump(schema *Schema, w io.Writer, options MarkdownOptions) error {
+	tpl, err := template.New("schema.tex").Delims("<<", ">>").Funcs(template.FuncMap{
+		"tex": func(val string) string {
+			val = latexEscaper.Replace(val)
+			return strings.ReplaceAll(val, "\n\n", "\n\\par\n")
+		},
+		"join":         strings.Join,
+		"linkable":     options.linkable,
+		"anchor":       anchor,
+		"snakeToTitle": snakeToTitle,
+	}).Parse(latexTemplate)
+	if err != nil {
+		return err
+	}
+	return tpl.Execute(w, schema)
+}
+
+// latexTemplate uses << >> delimiters as LaTeX is full of braces
+var latexTemplate = `\documentclass{article}
+\usepackage[utf8]{inputenc}
+\usepackage[T1]{fontenc}
+\usepackage{longtable}
+\usepackage{hyperref}
+
+\title{Database Schema}
+\date{\today}
+
+\begin{document}
+\maketitle
+\tableofcontents
+
+\section{Tables}
+<< range .Tables >>
+\subsection{<< tex (snakeToTitle .Name) >>}
+\label{<< anchor .Name >>}
+<< if or .FanIn .FanOut >>
+Referenced by << .FanIn >>, references << .FanOut >>.
+<< end >>
+<< tex .Description >>
+
+\begin{longtable}{|p{0.25\textwidth}|p{0.2\textwidth}|p{0.45\textwidth}|}
+\hline
+\textbf{Name} & \textbf{Type} & \textbf{Description} \\
+\hline
+\endhead
+<< range .KeyColumns ->>
+<< tex .Name >> (KEY) & << if linkable . >>\hyperref[<< anchor .ElementType >>]{<< tex .DataType >>}<< else >><< tex .DataType >><< end >> & << tex .Description >> \\
+\hline
+<< end ->>
+<< range .Columns ->>
+<< tex .Name >><< if .IsKey >> (KEY)<< end >><< if .Generated >> (GENERATED)<< end >> & << if linkable . >>\hyperref[<< anchor .ElementType >>]{<< tex .DataType >>}<< else >><< tex .DataType >><< end >> & << tex .Description >> \\
+\hline
+<< end ->>
+\end{longtable}
+<< if or .ForeignKeys .Polymorphic >>
+\begin{itemize}
+<< range .ForeignKeys ->>
+\item << tex .Name >><< if .Logical >> (logical)<< end >>: << tex .ColumnList >> $\rightarrow$ \hyperref[<< anchor .RefTable >>]{<< tex .RefTable >>}.<< tex .RefColumnList >>
+<< end ->>
+<< range .Polymorphic ->>
+\item << tex .Name >> (polymorphic on << tex .TypeColumn >>, << tex .IDColumn >><< if .Targets >>: << tex (join .Targets ", ") >><< end >>)
+<< end ->>
+\end{itemize}
+<< end ->>
+<< end >>
+<<- if .Enums >>
+\appendix
+\section{Enums}
+<< range .Enums >>
+\subsection{<< tex (snakeToTitle .Name) >>}
+\label{<< anchor .Name >>}
+
+<< tex .Description >>
+<< if .Transitions >>
+\begin{longtable}{|l|l|}
+\hline
+\textbf{From} & \textbf{To} \\
+\hline
+\endhead
+<< range .Transitions ->>
+<< tex .From >> & << tex .To >> \\
+\hline
+<< end ->>
+\end{longtable}
+<< else >>
+\begin{itemize}
+<< range .Values ->>
+\item << tex . >>
+<< end ->>
+\end{itemize}
+<< end ->>
+<< end >>
+<<- end >>
+<<- if .Functions >>
+<< if not .Enums >>\appendix<< end >>
+\section{Functions}
+<< range .Functions >>
+\subsection{<< tex .Name >>}
+
+\texttt{<< tex .Signature >>}<< if .Returns >> returns \texttt{<< tex .Returns >>}<< end >>
+
+<< tex .Description >>
+
+\begin{itemize}
+\item Kind: << tex .Kind >>
+\item Language: << tex .Language >>
+\item Volatility: << tex .Volatility >>
+\end{itemize}
+<< end >>
+<<- end >>
+\end{document}
+`
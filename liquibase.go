@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type liquibaseChangeLog struct {
+	XMLName        xml.Name             `xml:"databaseChangeLog"`
+	XMLNS          string               `xml:"xmlns,attr"`
+	XSI            string               `xml:"xmlns:xsi,attr"`
+	SchemaLocation string               `xml:"xsi:schemaLocation,attr"`
+	ChangeSets     []liquibaseChangeSet `xml:"changeSet"`
+}
+
+type liquibaseChangeSet struct {
+	ID           string                   `xml:"id,attr"`
+	Author       string                   `xml:"author,attr"`
+	SQL          []liquibaseSQL           `xml:"sql"`
+	CreateTables []liquibaseCreateTable   `xml:"createTable"`
+	ForeignKeys  []liquibaseAddForeignKey `xml:"addForeignKeyConstraint"`
+}
+
+type liquibaseSQL struct {
+	DBMS string `xml:"dbms,attr"`
+	SQL  string `xml:",chardata"`
+}
+
+type liquibaseCreateTable struct {
+	TableName string            `xml:"tableName,attr"`
+	Remarks   string            `xml:"remarks,attr,omitempty"`
+	Columns   []liquibaseColumn `xml:"column"`
+}
+
+type liquibaseColumn struct {
+	Name        string                `xml:"name,attr"`
+	Type        string                `xml:"type,attr"`
+	Remarks     string                `xml:"remarks,attr,omitempty"`
+	Constraints *liquibaseConstraints `xml:"constraints,omitempty"`
+}
+
+type liquibaseConstraints struct {
+	PrimaryKey     bool   `xml:"primaryKey,attr,omitempty"`
+	PrimaryKeyName string `xml:"primaryKeyName,attr,omitempty"`
+	Nullable       string `xml:"nullable,attr,omitempty"`
+}
+
+type liquibaseAddForeignKey struct {
+	ConstraintName        string `xml:"constraintName,attr"`
+	BaseTableName         string `xml:"baseTableName,attr"`
+	BaseColumnNames       string `xml:"baseColumnNames,attr"`
+	ReferencedTableName   string `xml:"referencedTableName,attr"`
+	ReferencedColumnNames string `xml:"referencedColumnNames,attr"`
+	Deferrable            bool   `xml:"deferrable,attr,omitempty"`
+	InitiallyDeferred     bool   `xml:"initiallyDeferred,attr,omitempty"`
+}
+
+// liquibaseDump writes a Liquibase XML changelog with a single changeset
+// recreating the schema, to adopt Liquibase on an existing database with
+// `changelog-sync`. Enums are created with raw SQL as Liquibase has no
+// change type for them. Logical keys aren't constraints, so are left out.
+func liquibaseDump(schema *Schema, w io.Writer) error {
+	changeSet := liquibaseChangeSet{
+		ID:     "pgdoc-baseline",
+		Author: "pgdoc",
+	}
+
+	for _, enum := range schema.Enums {
+		values := []string{}
+		for _, value := range enum.Values {
+			values = append(values, sqlQuote(value))
+		}
+		changeSet.SQL = append(changeSet.SQL, liquibaseSQL{
+			DBMS: "postgresql",
+			SQL:  fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", sqlIdentifier(enum.Name), strings.Join(values, ", ")),
+		})
+	}
+
+	for _, table := range schema.Tables {
+		create := liquibaseCreateTable{
+			TableName: table.Name,
+			Remarks:   table.Description,
+		}
+		for _, col := range table.allColumns() {
+			column := liquibaseColumn{
+				Name:    col.Name,
+				Type:    liquibaseType(col.DataType),
+				Remarks: col.Description,
+			}
+			if col.IsKey || !col.IsNullable {
+				column.Constraints = &liquibaseConstraints{}
+				if col.IsKey {
+					column.Constraints.PrimaryKey = true
+					column.Constraints.PrimaryKeyName = table.PrimaryKey
+				}
+				if !col.IsNullable {
+					column.Constraints.Nullable = "false"
+				}
+			}
+			create.Columns = append(create.Columns, column)
+		}
+		changeSet.CreateTables = append(changeSet.CreateTables, create)
+
+		for _, fk := range table.ForeignKeys {
+			if fk.Logical {
+				continue
+			}
+			changeSet.ForeignKeys = append(changeSet.ForeignKeys, liquibaseAddForeignKey{
+				ConstraintName:        fk.Name,
+				BaseTableName:         table.Name,
+				BaseColumnNames:       strings.Join(fk.Columns, ", "),
+				ReferencedTableName:   fk.RefTable,
+				ReferencedColumnNames: strings.Join(fk.RefColumns, ", "),
+				Deferrable:            fk.Deferrable,
+				InitiallyDeferred:     fk.InitiallyDeferred,
+			})
+		}
+	}
+
+	changeLog := liquibaseChangeLog{
+		XMLNS:          "http://www.liquibase.org/xml/ns/dbchangelog",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-latest.xsd",
+		ChangeSets:     []liquibaseChangeSet{changeSet},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(changeLog); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// liquibaseType converts the DataType getColumns reports back to a type
+// postgres accepts
+func liquibaseType(dataType string) string {
+	var precision, scale, length int
+	switch {
+	case strings.HasPrefix(dataType, "Number("):
+		if _, err := fmt.Sscanf(dataType, "Number(%d,%d)", &precision, &scale); err == nil {
+			return fmt.Sprintf("numeric(%d, %d)", precision, scale)
+		}
+		return "numeric"
+	case strings.HasPrefix(dataType, "Char("):
+		if _, err := fmt.Sscanf(dataType, "Char(%d)", &length); err == nil {
+			return fmt.Sprintf("char(%d)", length)
+		}
+		return "char"
+	case strings.HasSuffix(dataType, "[]"):
+		return liquibaseType(strings.TrimSuffix(dataType, "[]")) + "[]"
+	default:
+		return dataType
+	}
+}
+
+// sqlQuote quotes a string literal
+func sqlQuote(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+}
+
+// sqlIdentifier quotes an identifier when it needs to be
+func sqlIdentifier(name string) string {
+	if dbmlPlainName.MatchString(name) && strings.ToLower(name) == name {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
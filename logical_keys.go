@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// LogicalKeys declares keys which aren't backed by constraints, typically
+// for materialized views, keyed by table name:
+//
+//	{
+//	  "active_users": {
+//	    "primaryKey": ["id"],
+//	    "foreignKeys": [{"column": "org_id", "refTable": "orgs", "refColumn": "id"}]
+//	  }
+//	}
+type LogicalKeys map[string]LogicalKeyDeclaration
+
+type LogicalKeyDeclaration struct {
+	PrimaryKey  []string                `json:"primaryKey"`
+	ForeignKeys []LogicalForeignKeyDecl `json:"foreignKeys"`
+}
+
+type LogicalForeignKeyDecl struct {
+	Name      string `json:"name"`
+	Column    string `json:"column"`
+	RefTable  string `json:"refTable"`
+	RefColumn string `json:"refColumn"`
+}
+
+func readLogicalKeys(filename string) (LogicalKeys, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	keys := LogicalKeys{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("Reading %s: %w", filename, err)
+	}
+	return keys, nil
+}
+
+// logicalRelation is a table or view logical keys can be declared on
+type logicalRelation struct {
+	columns func() []ColumnDefinition
+
+	// setPrimaryKey marks the named columns as the key
+	setPrimaryKey func(isKey map[string]bool)
+
+	foreignKeys *[]ForeignKeyDefinition
+}
+
+func (r logicalRelation) hasColumn(name string) bool {
+	for _, col := range r.columns() {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// logicalRelations looks up the tables, views and materialized views by
+// name
+func logicalRelations(schema *Schema) map[string]logicalRelation {
+	relations := map[string]logicalRelation{}
+	for idx := range schema.Tables {
+		table := &schema.Tables[idx]
+		relations[table.Name] = logicalRelation{
+			columns: table.allColumns,
+			setPrimaryKey: func(isKey map[string]bool) {
+				keyColumns := []ColumnDefinition{}
+				restColumns := []ColumnDefinition{}
+				for _, col := range table.allColumns() {
+					col.IsKey = isKey[col.Name]
+					if col.IsKey {
+						keyColumns = append(keyColumns, col)
+					} else {
+						restColumns = append(restColumns, col)
+					}
+				}
+				table.KeyColumns = keyColumns
+				table.Columns = restColumns
+			},
+			foreignKeys: &table.ForeignKeys,
+		}
+	}
+	// Views keep their column order, only the flags change
+	addView := func(view *View) {
+		relations[view.Name] = logicalRelation{
+			columns: func() []ColumnDefinition { return view.Columns },
+			setPrimaryKey: func(isKey map[string]bool) {
+				for idx := range view.Columns {
+					view.Columns[idx].IsKey = isKey[view.Columns[idx].Name]
+				}
+			},
+			foreignKeys: &view.ForeignKeys,
+		}
+	}
+	for idx := range schema.Views {
+		addView(&schema.Views[idx])
+	}
+	for idx := range schema.MaterializedViews {
+		addView(&schema.MaterializedViews[idx].View)
+	}
+	return relations
+}
+
+// applyLogicalKeys adds declared keys to the schema as though they were
+// constraints, flagging the foreign keys as logical so they render dashed.
+// Keys can be declared on views and materialized views as well as tables.
+func applyLogicalKeys(schema *Schema, keys LogicalKeys) error {
+	relations := logicalRelations(schema)
+
+	for tableName, decl := range keys {
+		relation, ok := relations[tableName]
+		if !ok {
+			return fmt.Errorf("logical keys declared for unknown table or view %s", tableName)
+		}
+
+		if len(decl.PrimaryKey) > 0 {
+			isKey := map[string]bool{}
+			for _, name := range decl.PrimaryKey {
+				if !relation.hasColumn(name) {
+					return fmt.Errorf("logical primary key column %s.%s does not exist", tableName, name)
+				}
+				isKey[name] = true
+			}
+			relation.setPrimaryKey(isKey)
+		}
+
+		for _, fk := range decl.ForeignKeys {
+			if !relation.hasColumn(fk.Column) {
+				return fmt.Errorf("logical foreign key column %s.%s does not exist", tableName, fk.Column)
+			}
+			refRelation, ok := relations[fk.RefTable]
+			if !ok {
+				return fmt.Errorf("logical foreign key %s.%s references unknown table or view %s", tableName, fk.Column, fk.RefTable)
+			}
+			if !refRelation.hasColumn(fk.RefColumn) {
+				return fmt.Errorf("logical foreign key %s.%s references unknown column %s.%s", tableName, fk.Column, fk.RefTable, fk.RefColumn)
+			}
+			name := fk.Name
+			if name == "" {
+				name = fmt.Sprintf("%s_%s_fkey", tableName, fk.Column)
+			}
+			*relation.foreignKeys = append(*relation.foreignKeys, ForeignKeyDefinition{
+				Column:     fk.Column,
+				Name:       name,
+				RefTable:   fk.RefTable,
+				RefColumn:  fk.RefColumn,
+				Columns:    []string{fk.Column},
+				RefColumns: []string{fk.RefColumn},
+				Logical:    true,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestLogicalKeysOnMaterializedView declares a key from a materialized view
+// to a table and checks the diagrams draw it as logical
+func TestLogicalKeysOnMaterializedView(t *testing.T) {
+	file, err := ioutil.TempFile("", "pgdoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(`{
+  "org_sizes": {
+    "primaryKey": ["org_id"],
+    "foreignKeys": [{"column": "org_id", "refTable": "orgs", "refColumn": "id"}]
+  }
+}`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	keys, err := readLogicalKeys(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := &Schema{
+		Tables: []Table{{
+			Name:       "orgs",
+			KeyColumns: []ColumnDefinition{{Name: "id", DataType: "integer", IsKey: true}},
+			Columns:    []ColumnDefinition{{Name: "name", DataType: "text"}},
+		}},
+		MaterializedViews: []MaterializedView{{
+			View: View{
+				Name: "org_sizes",
+				Columns: []ColumnDefinition{
+					{Name: "members", DataType: "bigint", IsNullable: true},
+					{Name: "org_id", DataType: "integer", IsNullable: true},
+				},
+			},
+			Populated: true,
+		}},
+	}
+	if err := applyLogicalKeys(schema, keys); err != nil {
+		t.Fatal(err)
+	}
+
+	view := schema.MaterializedViews[0]
+	if !view.Columns[1].IsKey || view.Columns[0].IsKey {
+		t.Errorf("expected org_id to be the key, got %+v", view.Columns)
+	}
+
+	puml := &bytes.Buffer{}
+	pumlDump(schema, puml, PUMLOptions{IncludeColumns: true})
+	for _, want := range []string{
+		"entity org_sizes <<materialized>> {\n  org_id\n--\n  members\n}",
+		"org_sizes }|..|| orgs\n",
+	} {
+		if !strings.Contains(puml.String(), want) {
+			t.Errorf("expected PlantUML to contain %q, got:\n%s", want, puml)
+		}
+	}
+
+	mermaid := &bytes.Buffer{}
+	if err := mermaidDump(schema, mermaid, MermaidOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "  org_sizes }o..o| orgs : org_id\n"; !strings.Contains(mermaid.String(), want) {
+		t.Errorf("expected Mermaid to contain %q, got:\n%s", want, mermaid)
+	}
+
+	md := &bytes.Buffer{}
+	if err := mdDump(schema, md, MarkdownOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| org_id (KEY) |",
+		"org_sizes_org_id_fkey (logical)",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+
+	keys["org_sizes"] = LogicalKeyDeclaration{
+		ForeignKeys: []LogicalForeignKeyDecl{{Column: "org_id", RefTable: "teams", RefColumn: "id"}},
+	}
+	if err := applyLogicalKeys(schema, keys); err == nil {
+		t.Error("expected an error for a key into an unknown table")
+	}
+}
@@ -1,19 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	sq "github.com/elgris/sqrl"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	sqrlx "gopkg.daemonl.com/sqrlx"
 )
 
@@ -28,119 +31,623 @@ func (i *arrayFlags) Set(value string) error {
 	return nil
 }
 
+// schemaMigrationTables are the bookkeeping tables of common migration
+// tools, dropped by -exclude-schema-migrations
+var schemaMigrationTables = []string{
+	"schema_migrations",     // Rails, golang-migrate, dbmate
+	"ar_internal_metadata",  // Rails
+	"flyway_schema_history", // Flyway
+	"goose_db_version",      // goose
+	"databasechangelog",     // Liquibase
+	"databasechangeloglock", // Liquibase
+	"knex_migrations",       // Knex
+	"knex_migrations_lock",  // Knex
+}
+
 type Config struct {
+	// Include and Exclude pick the tables and views to document by name,
+	// glob or regular expression, see NameFilter
+	Include     []string
 	Exclude     []string
 	PostgresURL string
+
+	// Schemas are the postgres schemas to document, public when empty.
+	// AllSchemas documents every schema other than postgres' own instead.
+	Schemas    []string
+	AllSchemas bool
+
+	// MaxReplicaLag refuses to introspect a replica further behind its
+	// primary than this, or only warns with ReplicaLagWarnOnly
+	MaxReplicaLag      time.Duration
+	ReplicaLagWarnOnly bool
+
+	// Privileges reads the grants on each table
+	Privileges bool
+
+	// Publications reads the logical replication publications
+	Publications bool
+
+	// EventTriggers reads the triggers on DDL events
+	EventTriggers bool
+
+	// Stats reads the planner's statistics on each column
+	Stats bool
+
+	// Sizes reads each table's estimated row count and size on disk
+	Sizes bool
+
+	// Sample reads this many example rows of each table
+	Sample int
+
+	// ExcludeColumns are left out of the tables and views entirely, while
+	// Redact columns are documented without any of their data. Both are
+	// named alone or as table.column.
+	ExcludeColumns []string
+	Redact         []string
+
+	// Profile queries the data of the columns it selects
+	Profile ProfileOptions
+
+	// Maintenance reads when each table was last vacuumed and analyzed
+	Maintenance bool
+
+	// InheritViewComments fills in view columns without a comment from the
+	// column they select
+	InheritViewComments bool
 }
 
 func main() {
-	var exclude arrayFlags
-	flag.Var(&exclude, "exclude", "Tables to exclude")
-	pgURL := flag.String("postgres", "", "Postgres URL")
+	if err := runCommand(os.Args[1:]); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// logSkipped logs anything which couldn't be introspected, the rest of the
+// report is only written when asked for.
+func logSkipped(prefix string, report *Report) {
+	for _, warning := range report.Warnings {
+		if warning.Kind == warnSkipped {
+			warnf("%s%s", prefix, warning.String())
+		}
+	}
+}
+
+// SchemaOptions are the post processing steps applied to each schema
+// before it is rendered
+type SchemaOptions struct {
+	Descriptions         map[string]string
+	DescriptionsFillOnly bool
 
-	pumlOutFile := flag.String("puml", "", "PUML Output File")
-	jsonOutFile := flag.String("json", "", "JSON Output File")
-	mdOutFile := flag.String("md", "", "MD Output File")
+	// EnumStateMachines parses state transitions from enum comments
+	EnumStateMachines bool
 
-	pumlNoColumns := flag.Bool("puml-skip-columns", false, "Skip columns in PUML output")
-	pumlInclTypes := flag.Bool("puml-include-types", false, "Include data types in PUML")
+	LogicalKeys LogicalKeys
+	Polymorphic *PolymorphicOptions
+	ColumnOrder string
+}
 
-	flag.Parse()
-	config := Config{
-		Exclude:     []string(exclude),
-		PostgresURL: *pgURL,
+func prepareSchema(schema *Schema, options SchemaOptions) error {
+	if options.Descriptions != nil {
+		applyDescriptions(schema, options.Descriptions, options.DescriptionsFillOnly)
 	}
 
-	fullSchema, err := getSchema(config)
-	if err != nil {
-		log.Fatal(err.Error())
+	if options.EnumStateMachines {
+		addTransitions(schema)
 	}
 
-	if *pumlOutFile != "" {
-		withWriter(*pumlOutFile, func(w io.Writer) error {
-			pumlOptions := PUMLOptions{
-				IncludeColumns:   !*pumlNoColumns,
-				IncludeDataTypes: *pumlInclTypes,
-			}
-			pumlDump(fullSchema, w, pumlOptions)
-			return nil
-		})
+	if options.LogicalKeys != nil {
+		if err := applyLogicalKeys(schema, options.LogicalKeys); err != nil {
+			return err
+		}
 	}
 
-	if *jsonOutFile != "" {
-		withWriter(*jsonOutFile, func(w io.Writer) error {
-			bytes, err := json.MarshalIndent(fullSchema, "", "  ")
-			if err != nil {
-				return err
-			}
-			if _, err := w.Write(bytes); err != nil {
-				return err
-			}
+	if options.Polymorphic != nil {
+		addPolymorphicAssociations(schema, *options.Polymorphic)
+	}
+
+	if options.ColumnOrder != "" {
+		orderColumns(schema, options.ColumnOrder)
+	}
+
+	countReferences(schema)
+	addColumnChecks(schema)
+	addViewUsage(schema)
+	addEnumUsage(schema)
+	return nil
+}
+
+// Outputs are the files to render, formats with an empty filename are
+// skipped
+type Outputs struct {
+	PUML        string
+	PNG         string
+	SVG         string
+	JSON        string
+	YAML        string
+	NDJSON      string
+	Markdown    string
+	MarkdownDir string
+	AsciiDoc    string
+	LaTeX       string
+	HTML        string
+	Site        string
+	Confluence  string
+	Docusaurus  string
+	MkDocs      string
+	Hugo        string
+	SQLite      string
+	CSV         string
+	GraphQL     string
+	Proto       string
+	JSONSchema  string
+	Avro        string
+	TypeScript  string
+	Go          string
+	OpenAPI     string
+	DBT         string
+	Liquibase   string
+	DBML        string
+	D2          string
+	DrawIO      string
+	Mermaid     string
+	MermaidFlow string
+	PUMLJSON    string
+
+	// ViewsPUML and ViewsMermaid are diagrams of which views select from
+	// which tables and views
+	ViewsPUML    string
+	ViewsMermaid string
+
+	PUMLJSONTable   string
+	PlantUMLServer  string
+	ProtoPackage    string
+	AvroNamespace   string
+	GoPackage       string
+	DBTSource       string
+	PUMLOptions     PUMLOptions
+	MermaidOptions  MermaidOptions
+	MarkdownOptions MarkdownOptions
+
+	// CollapsePartitions leaves partitions out of the diagrams
+	CollapsePartitions bool
+
+	// Archive packages the other outputs into a zip file
+	Archive string
+
+	// Header and Footer surround the documents and PUML diagrams. Data and
+	// code outputs are left alone, as they'd no longer parse.
+	Header string
+	Footer string
+}
+
+// writeOutputs renders each requested output. When dir is set, filenames
+// are placed inside it.
+func writeOutputs(schema *Schema, outputs Outputs, dir string) error {
+	path := func(filename string) string {
+		if dir == "" || filename == "-" {
+			return filename
+		}
+		return filepath.Join(dir, filename)
+	}
+
+	if outputs.Archive != "" {
+		return writeArchive(schema, outputs, path(outputs.Archive))
+	}
+
+	diagram := schema
+	if outputs.CollapsePartitions {
+		diagram = collapsePartitions(schema)
+	}
+
+	if outputs.PUML != "" {
+		if err := withWriter(path(outputs.PUML), func(w io.Writer) error {
+			pumlDump(diagram, w, outputs.PUMLOptions)
 			return nil
-		})
+		}); err != nil {
+			return err
+		}
 	}
 
-	if *mdOutFile != "" {
-		withWriter(*mdOutFile, func(w io.Writer) error {
-			return mdDump(fullSchema, w)
-		})
+	for format, filename := range map[string]string{"png": outputs.PNG, "svg": outputs.SVG} {
+		if filename == "" {
+			continue
+		}
+		puml := &strings.Builder{}
+		pumlDump(diagram, puml, outputs.PUMLOptions)
+		if err := withWriter(path(filename), func(w io.Writer) error {
+			return plantumlRender(outputs.PlantUMLServer, format, puml.String(), w)
+		}); err != nil {
+			return fmt.Errorf("Rendering %s: %w", filename, err)
+		}
+	}
+
+	if outputs.JSON != "" {
+		if err := withWriter(path(outputs.JSON), func(w io.Writer) error {
+			return jsonDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.NDJSON != "" {
+		if err := withWriter(path(outputs.NDJSON), func(w io.Writer) error {
+			return ndjsonDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.YAML != "" {
+		if err := withWriter(path(outputs.YAML), func(w io.Writer) error {
+			return yamlDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Markdown != "" {
+		if err := withWriter(path(outputs.Markdown), outputs.MarkdownOptions.decorated(func(w io.Writer) error {
+			return mdDump(schema, w, outputs.MarkdownOptions)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if outputs.MarkdownDir != "" {
+		if err := markdownDirDump(schema, path(outputs.MarkdownDir), outputs.MarkdownOptions); err != nil {
+			return err
+		}
+	}
+
+	if outputs.AsciiDoc != "" {
+		if err := withWriter(path(outputs.AsciiDoc), outputs.MarkdownOptions.decorated(func(w io.Writer) error {
+			return adocDump(schema, w, outputs.MarkdownOptions)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if outputs.LaTeX != "" {
+		if err := withWriter(path(outputs.LaTeX), outputs.MarkdownOptions.decoratedWithin(`\begin{document}`, `\end{document}`, func(w io.Writer) error {
+			return latexDump(schema, w, outputs.MarkdownOptions)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if outputs.HTML != "" {
+		if err := withWriter(path(outputs.HTML), outputs.MarkdownOptions.decoratedWithin("<body>", "</body>", func(w io.Writer) error {
+			return htmlDump(schema, w, outputs.MarkdownOptions)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Site != "" {
+		if err := siteDump(schema, path(outputs.Site), outputs.MarkdownOptions); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Docusaurus != "" {
+		if err := docusaurusDump(schema, path(outputs.Docusaurus), outputs.MarkdownOptions); err != nil {
+			return err
+		}
+	}
+
+	if outputs.MkDocs != "" {
+		if err := mkdocsDump(schema, path(outputs.MkDocs), outputs.MarkdownOptions); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Hugo != "" {
+		if err := hugoDump(schema, path(outputs.Hugo), outputs.MarkdownOptions); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Confluence != "" {
+		if err := withWriter(path(outputs.Confluence), outputs.MarkdownOptions.decorated(func(w io.Writer) error {
+			return confluenceDump(schema, w, outputs.MarkdownOptions)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if outputs.PUMLJSON != "" {
+		if err := withWriter(path(outputs.PUMLJSON), func(w io.Writer) error {
+			return pumlJSONDump(diagram, w, outputs.PUMLJSONTable)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.DBML != "" {
+		if err := withWriter(path(outputs.DBML), func(w io.Writer) error {
+			return dbmlDump(diagram, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.D2 != "" {
+		if err := withWriter(path(outputs.D2), func(w io.Writer) error {
+			return d2Dump(diagram, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.DrawIO != "" {
+		if err := withWriter(path(outputs.DrawIO), func(w io.Writer) error {
+			return drawioDump(diagram, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Mermaid != "" {
+		if err := withWriter(path(outputs.Mermaid), func(w io.Writer) error {
+			return mermaidDump(diagram, w, outputs.MermaidOptions)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.MermaidFlow != "" {
+		if err := withWriter(path(outputs.MermaidFlow), func(w io.Writer) error {
+			return mermaidFlowDump(diagram, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.ViewsPUML != "" {
+		if err := withWriter(path(outputs.ViewsPUML), func(w io.Writer) error {
+			return viewDependenciesPUMLDump(schema, w, outputs.PUMLOptions)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.ViewsMermaid != "" {
+		if err := withWriter(path(outputs.ViewsMermaid), func(w io.Writer) error {
+			return viewDependenciesMermaidDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.CSV != "" {
+		if err := csvDump(schema, path(outputs.CSV)); err != nil {
+			return err
+		}
+	}
+
+	if outputs.GraphQL != "" {
+		if err := withWriter(path(outputs.GraphQL), func(w io.Writer) error {
+			return graphqlDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Proto != "" {
+		if err := withWriter(path(outputs.Proto), func(w io.Writer) error {
+			return protoDump(schema, w, outputs.ProtoPackage)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.JSONSchema != "" {
+		if err := jsonSchemaDump(schema, path(outputs.JSONSchema)); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Avro != "" {
+		if err := avroDump(schema, path(outputs.Avro), outputs.AvroNamespace); err != nil {
+			return err
+		}
+	}
+
+	if outputs.TypeScript != "" {
+		if err := withWriter(path(outputs.TypeScript), func(w io.Writer) error {
+			return typescriptDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.Go != "" {
+		if err := withWriter(path(outputs.Go), func(w io.Writer) error {
+			return goDump(schema, w, outputs.GoPackage)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.OpenAPI != "" {
+		if err := withWriter(path(outputs.OpenAPI), func(w io.Writer) error {
+			return openapiDump(schema, w, isYAMLFile(outputs.OpenAPI))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.DBT != "" {
+		if err := withWriter(path(outputs.DBT), func(w io.Writer) error {
+			return dbtDump(schema, w, outputs.DBTSource)
+		}); err != nil {
+			return err
+		}
 	}
+
+	if outputs.Liquibase != "" {
+		if err := withWriter(path(outputs.Liquibase), func(w io.Writer) error {
+			return liquibaseDump(schema, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputs.SQLite != "" {
+		if err := sqliteDump(schema, path(outputs.SQLite)); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func withWriter(filename string, callback func(io.Writer) error) {
-	if filename == "-" {
-		if err := callback(os.Stdout); err != nil {
-			log.Fatal(err.Error())
+// decorated wraps a document's callback to write the header and footer
+// around it
+func (o MarkdownOptions) decorated(callback func(io.Writer) error) func(io.Writer) error {
+	return func(w io.Writer) error {
+		if _, err := io.WriteString(w, o.Header); err != nil {
+			return err
 		}
-		return
+		if err := callback(w); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, o.Footer)
+		return err
+	}
+}
+
+// decoratedWithin is decorated for a document whose content has to go
+// between markup, like the body of an HTML page: the header follows the
+// first line with start, and the footer precedes end.
+func (o MarkdownOptions) decoratedWithin(start string, end string, callback func(io.Writer) error) func(io.Writer) error {
+	return func(w io.Writer) error {
+		buf := &bytes.Buffer{}
+		if err := callback(buf); err != nil {
+			return err
+		}
+		doc := buf.String()
+		startIdx := strings.Index(doc, start)
+		endIdx := strings.LastIndex(doc, end)
+		if startIdx < 0 || endIdx < startIdx {
+			return o.decorated(func(w io.Writer) error {
+				_, err := io.WriteString(w, doc)
+				return err
+			})(w)
+		}
+		if lineEnd := strings.Index(doc[startIdx:], "\n"); lineEnd >= 0 && startIdx+lineEnd < endIdx {
+			startIdx += lineEnd + 1
+		} else {
+			startIdx += len(start)
+		}
+		_, err := io.WriteString(w, doc[:startIdx]+o.Header+doc[startIdx:endIdx]+o.Footer+doc[endIdx:])
+		return err
+	}
+}
+
+func withWriter(filename string, callback func(io.Writer) error) error {
+	if filename == "-" {
+		return callback(os.Stdout)
 	}
 	out, err := os.Create(filename)
 	if err != nil {
-		log.Fatal(err.Error())
+		return err
 	}
 	defer out.Close()
-	if err := callback(out); err != nil {
-		log.Fatal(err.Error())
-	}
+	return callback(out)
 }
 
-func getSchema(config Config) (*Schema, error) {
+func getSchema(config Config) (*Schema, *Report, error) {
 
-	schema := "public"
 	ctx := context.Background()
 	conn, err := sql.Open("postgres", config.PostgresURL)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := conn.Ping(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	db, err := sqrlx.New(conn, sq.Dollar)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if config.MaxReplicaLag > 0 {
+		isReplica, lag, err := getReplicaLag(ctx, db)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkReplicaLag(isReplica, lag, config.MaxReplicaLag); err != nil {
+			if !config.ReplicaLagWarnOnly {
+				return nil, nil, err
+			}
+			warnf("%s", err.Error())
+		}
 	}
 
-	return getFullSchema(ctx, db, schema, config)
+	return Introspect(ctx, db, config)
 
 }
 
-func getFullSchema(ctx context.Context, db *sqrlx.Wrapper, schema string, config Config) (*Schema, error) {
-	tables, err := getTableNames(ctx, db, schema, config.Exclude)
+// Introspect reads the schema from the database, along with a Report of
+// what was found and anything which couldn't be read.
+func Introspect(ctx context.Context, db *sqrlx.Wrapper, config Config) (*Schema, *Report, error) {
+	schemas := config.Schemas
+	if config.AllSchemas {
+		var err error
+		schemas, err = getSchemaNames(ctx, db)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if len(schemas) == 0 {
+		schemas = []string{"public"}
+	}
+	report := newReport()
+	fullSchema, err := getSchemas(ctx, db, schemas, config, report)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.analyse(fullSchema)
+	return fullSchema, report, nil
+}
+
+func getFullSchema(ctx context.Context, db *sqrlx.Wrapper, schema string, config Config, report *Report) (*Schema, error) {
+	filter, err := newNameFilter(config.Include, config.Exclude)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
+	tables, err := getTableNames(ctx, db, schema, filter)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("tables", start)
+
 	for idx, table := range tables {
+		start := time.Now()
 		cols, err := getColumns(ctx, db, schema, table.Name)
 		if err != nil {
 			return nil, err
 		}
+		cols = withoutColumns(cols, config.ExcludeColumns, table.Name)
+		report.timed("columns", start)
 
+		start = time.Now()
 		constraints, err := getConstraints(ctx, db, schema, table.Name)
 		if err != nil {
 			return nil, err
 		}
+		report.timed("constraints", start)
+
+		start = time.Now()
+		indexes, err := getIndexes(ctx, db, schema, table.Name)
+		if err != nil {
+			return nil, err
+		}
+		tables[idx].Indexes = indexes
+		report.timed("indexes", start)
 
 		pkCols := map[string]ConstraintDefinition{}
 		fkCols := []ForeignKeyDefinition{}
@@ -154,20 +661,55 @@ func getFullSchema(ctx context.Context, db *sqrlx.Wrapper, schema string, config
 					}
 					pkCols[column.Column] = constraint
 				}
+				tables[idx].PrimaryKey = constraint.ConstraintName
 			case "FOREIGN KEY":
-				if len(constraint.LocalColumns) != 1 || len(constraint.ForeignColumns) != 1 {
-					return nil, fmt.Errorf("foreign keys should have 1 local, 1 foreign column. See %s", constraint.ConstraintName)
+				// Only the referenced table's owner can see its side
+				if len(constraint.ForeignColumns) == 0 {
+					report.warn(warnSkipped, "foreign key "+constraint.ConstraintName, "referenced columns are not visible to this role")
+					continue
+				}
+				if len(constraint.LocalColumns) == 0 || len(constraint.LocalColumns) != len(constraint.ForeignColumns) {
+					return nil, fmt.Errorf("foreign key %s has %d local and %d foreign columns", constraint.ConstraintName, len(constraint.LocalColumns), len(constraint.ForeignColumns))
 				}
-				localCol := constraint.LocalColumns[0]
-				if localCol.Table != table.Name {
-					return nil, fmt.Errorf("Table %s had foreign key %s in %s", table.Name, constraint.ConstraintName, localCol.Table)
+				fk := ForeignKeyDefinition{
+					Name:     constraint.ConstraintName,
+					RefTable: constraint.ForeignColumns[0].Table,
+					OnDelete: constraint.OnDelete,
+					OnUpdate: constraint.OnUpdate,
+
+					Deferrable:        constraint.Deferrable,
+					InitiallyDeferred: constraint.InitiallyDeferred,
 				}
-				foreignCol := constraint.ForeignColumns[0]
-				fkCols = append(fkCols, ForeignKeyDefinition{
-					Column:    localCol.Column,
-					Name:      constraint.ConstraintName,
-					RefTable:  foreignCol.Table,
-					RefColumn: foreignCol.Column,
+				if refSchema := constraint.ForeignColumns[0].Schema; refSchema != "" && refSchema != schema {
+					fk.RefSchema = refSchema
+					fk.RefTable = refSchema + "." + fk.RefTable
+				}
+				for colIdx, localCol := range constraint.LocalColumns {
+					if localCol.Table != table.Name {
+						return nil, fmt.Errorf("Table %s had foreign key %s in %s", table.Name, constraint.ConstraintName, localCol.Table)
+					}
+					fk.Columns = append(fk.Columns, localCol.Column)
+					fk.RefColumns = append(fk.RefColumns, constraint.ForeignColumns[colIdx].Column)
+				}
+				fk.Column = fk.Columns[0]
+				fk.RefColumn = fk.RefColumns[0]
+				fkCols = append(fkCols, fk)
+
+			case "UNIQUE":
+				unique := UniqueConstraint{
+					Name:              constraint.ConstraintName,
+					Deferrable:        constraint.Deferrable,
+					InitiallyDeferred: constraint.InitiallyDeferred,
+				}
+				for _, column := range constraint.LocalColumns {
+					unique.Columns = append(unique.Columns, column.Column)
+				}
+				tables[idx].UniqueConstraints = append(tables[idx].UniqueConstraints, unique)
+			case "CHECK":
+				tables[idx].CheckConstraints = append(tables[idx].CheckConstraints, CheckConstraint{
+					Name:       constraint.ConstraintName,
+					Definition: constraint.Definition,
+					Columns:    constraint.CheckColumns,
 				})
 
 			default:
@@ -180,6 +722,7 @@ func getFullSchema(ctx context.Context, db *sqrlx.Wrapper, schema string, config
 
 		for _, col := range cols {
 			if _, ok := pkCols[col.Name]; ok {
+				col.IsKey = true
 				keyColumns = append(keyColumns, col)
 			} else {
 				restColumns = append(restColumns, col)
@@ -191,16 +734,217 @@ func getFullSchema(ctx context.Context, db *sqrlx.Wrapper, schema string, config
 		tables[idx].ForeignKeys = fkCols
 	}
 
+	start = time.Now()
+	if err := addExclusionConstraints(ctx, db, schema, tables); err != nil {
+		return nil, err
+	}
+	report.timed("exclusions", start)
+
+	start = time.Now()
+	views, err := getViews(ctx, db, schema, filter)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("views", start)
+
+	start = time.Now()
+	matviews, err := getMaterializedViews(ctx, db, schema, filter)
+	if err != nil {
+		return nil, err
+	}
+	for idx, view := range views {
+		views[idx].Columns = withoutColumns(view.Columns, config.ExcludeColumns, view.Name)
+	}
+	for idx, view := range matviews {
+		matviews[idx].Columns = withoutColumns(view.Columns, config.ExcludeColumns, view.Name)
+	}
+	report.timed("materialized views", start)
+
+	start = time.Now()
+	if err := addViewDependencies(ctx, db, schema, views, matviews, report); err != nil {
+		return nil, err
+	}
+	report.timed("view dependencies", start)
+
+	start = time.Now()
 	enums, err := getEnums(ctx, db, schema)
 	if err != nil {
 		return nil, err
 	}
+	report.timed("enums", start)
+
+	start = time.Now()
+	domains, err := getDomains(ctx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("domains", start)
 
-	return &Schema{
+	start = time.Now()
+	types, err := getCompositeTypes(ctx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("types", start)
+
+	start = time.Now()
+	functions, err := getFunctions(ctx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("functions", start)
+
+	start = time.Now()
+	if err := addDescriptions(ctx, db, schema, tables, views, matviews, enums, types, report); err != nil {
+		return nil, err
+	}
+	report.timed("descriptions", start)
+
+	if config.InheritViewComments {
+		start = time.Now()
+		if err := inheritViewComments(ctx, db, schema, views, matviews, config.ExcludeColumns, report); err != nil {
+			return nil, err
+		}
+		report.timed("view column origins", start)
+	}
+
+	start = time.Now()
+	if err := addStorage(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("storage", start)
+
+	start = time.Now()
+	if err := addPartitions(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("partitions", start)
+
+	start = time.Now()
+	if err := addForeignTables(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("foreign tables", start)
+
+	start = time.Now()
+	if err := addPolicies(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("policies", start)
+
+	start = time.Now()
+	if err := addTriggers(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("triggers", start)
+
+	start = time.Now()
+	if err := addRules(ctx, db, schema, tables, report); err != nil {
+		return nil, err
+	}
+	report.timed("rules", start)
+
+	if config.Privileges {
+		start = time.Now()
+		if err := addGrants(ctx, db, schema, tables, report); err != nil {
+			return nil, err
+		}
+		report.timed("privileges", start)
+	}
+
+	if config.Stats {
+		start = time.Now()
+		if err := addColumnStats(ctx, db, schema, tables, config.Redact, report); err != nil {
+			return nil, err
+		}
+		report.timed("stats", start)
+	}
+
+	if config.Sizes {
+		start = time.Now()
+		if err := addSizes(ctx, db, schema, tables, report); err != nil {
+			return nil, err
+		}
+		report.timed("sizes", start)
+	}
+
+	if config.Maintenance {
+		start = time.Now()
+		if err := addMaintenance(ctx, db, schema, tables, report); err != nil {
+			return nil, err
+		}
+		report.timed("maintenance", start)
+	}
+
+	if config.Sample > 0 {
+		start = time.Now()
+		if err := addSamples(ctx, db, schema, tables, config.Sample, config.Redact, report); err != nil {
+			return nil, err
+		}
+		report.timed("sample", start)
+	}
+
+	if len(config.Profile.Columns) > 0 {
+		start = time.Now()
+		if err := addProfiles(ctx, db, schema, tables, config.Profile, config.Redact, report); err != nil {
+			return nil, err
+		}
+		report.timed("profile", start)
+	}
+
+	start = time.Now()
+	sequences, err := getSequences(ctx, db, schema, tables, report)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("sequences", start)
+
+	start = time.Now()
+	extensions, err := getExtensions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	report.timed("extensions", start)
+
+	var publications []Publication
+	if config.Publications {
+		start = time.Now()
+		publications, err = getPublications(ctx, db, schema, tables, report)
+		if err != nil {
+			return nil, err
+		}
+		report.timed("publications", start)
+	}
+
+	var eventTriggers []EventTrigger
+	if config.EventTriggers {
+		start = time.Now()
+		eventTriggers, err = getEventTriggers(ctx, db, report)
+		if err != nil {
+			return nil, err
+		}
+		report.timed("event triggers", start)
+	}
+
+	fullSchema := &Schema{
 
 		Tables: tables,
+		Views:  views,
 		Enums:  enums,
-	}, nil
+
+		MaterializedViews: matviews,
+		Domains:           domains,
+		Types:             types,
+		Functions:         functions,
+		Sequences:         sequences,
+		Extensions:        extensions,
+		Publications:      publications,
+		EventTriggers:     eventTriggers,
+	}
+	// The columns themselves were never read, but constraints and indexes
+	// over them were
+	excludeColumns(fullSchema, config.ExcludeColumns)
+	return fullSchema, nil
 }
 
 type ForeignKeyDefinition struct {
@@ -208,14 +952,122 @@ type ForeignKeyDefinition struct {
 	Name      string
 	RefTable  string
 	RefColumn string
+
+	// RefSchema is set when the referenced table is in another schema,
+	// RefTable then being qualified with it
+	RefSchema string `json:",omitempty"`
+
+	// Columns and RefColumns pair up every column of the key, in order.
+	// Column and RefColumn are the first pair, which is the whole key
+	// unless it is composite.
+	Columns    []string
+	RefColumns []string
+
+	// OnDelete and OnUpdate are the referential actions, like CASCADE or
+	// SET NULL
+	OnDelete string `json:",omitempty"`
+	OnUpdate string `json:",omitempty"`
+
+	// Logical keys are declared rather than enforced by a constraint
+	Logical bool `json:",omitempty"`
+
+	// Deferrable keys may be checked at commit rather than per statement,
+	// and are by default when InitiallyDeferred
+	Deferrable        bool `json:",omitempty"`
+	InitiallyDeferred bool `json:",omitempty"`
+
+	Description string `json:",omitempty"`
+}
+
+// Deferral is DEFERRABLE, with INITIALLY DEFERRED when it is, or empty for
+// keys checked immediately
+func (fk ForeignKeyDefinition) Deferral() string {
+	return deferral(fk.Deferrable, fk.InitiallyDeferred)
+}
+
+func deferral(deferrable bool, initiallyDeferred bool) string {
+	switch {
+	case initiallyDeferred:
+		return "DEFERRABLE INITIALLY DEFERRED"
+	case deferrable:
+		return "DEFERRABLE"
+	default:
+		return ""
+	}
+}
+
+// Actions describes the referential actions other than the default NO
+// ACTION, empty when there are none
+func (fk ForeignKeyDefinition) Actions() string {
+	actions := []string{}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		actions = append(actions, "ON DELETE "+fk.OnDelete)
+	}
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		actions = append(actions, "ON UPDATE "+fk.OnUpdate)
+	}
+	return strings.Join(actions, ", ")
+}
+
+// IsCrossSchema is true for keys referencing a table in another schema
+func (fk ForeignKeyDefinition) IsCrossSchema() bool {
+	return fk.RefSchema != ""
+}
+
+// IsComposite is true for keys over more than one column
+func (fk ForeignKeyDefinition) IsComposite() bool {
+	return len(fk.Columns) > 1
+}
+
+// ColumnList is the local columns, parenthesised when there are several
+func (fk ForeignKeyDefinition) ColumnList() string {
+	return columnList(fk.Columns)
+}
+
+// RefColumnList is the referenced columns, parenthesised when there are
+// several
+func (fk ForeignKeyDefinition) RefColumnList() string {
+	return columnList(fk.RefColumns)
+}
+
+func columnList(columns []string) string {
+	if len(columns) == 1 {
+		return columns[0]
+	}
+	return "(" + strings.Join(columns, ", ") + ")"
+}
+
+// CheckConstraint is a CHECK constraint. Definition is as postgres
+// reconstructs it, including the CHECK keyword.
+type CheckConstraint struct {
+	Name        string `json:"name"`
+	Definition  string `json:"definition"`
+	Description string `json:"description,omitempty"`
+
+	// Columns are the columns the check refers to
+	Columns []string `json:"columns,omitempty"`
+}
+
+// UniqueConstraint is a UNIQUE constraint over one or more columns
+type UniqueConstraint struct {
+	Name        string   `json:"name"`
+	Columns     []string `json:"columns"`
+	Description string   `json:"description,omitempty"`
+
+	Deferrable        bool `json:"deferrable,omitempty"`
+	InitiallyDeferred bool `json:"initiallyDeferred,omitempty"`
+}
+
+// Deferral is as for ForeignKeyDefinition
+func (u UniqueConstraint) Deferral() string {
+	return deferral(u.Deferrable, u.InitiallyDeferred)
 }
 
 func getEnums(ctx context.Context, db *sqrlx.Wrapper, schema string) ([]Enum, error) {
 
 	rows, err := db.QueryRaw(ctx, `
 		SELECT t.typname,
-			string_agg(e.enumlabel, '|' ORDER BY e.enumsortorder) AS enum_labels,
-			COALESCE(obj_description(t.oid, 'pg_type'), '')
+			string_agg(e.enumlabel, '|' ORDER BY e.enumsortorder) AS enum_labels
 		FROM   pg_catalog.pg_type t
 		JOIN   pg_catalog.pg_namespace n ON n.oid = t.typnamespace 
 		JOIN   pg_catalog.pg_enum e ON t.oid = e.enumtypid
@@ -228,56 +1080,340 @@ func getEnums(ctx context.Context, db *sqrlx.Wrapper, schema string) ([]Enum, er
 	enums := make([]Enum, 0)
 	for rows.Next() {
 		name := ""
-		description := ""
 		valsRaw := ""
-		if err := rows.Scan(&name, &valsRaw, &description); err != nil {
+		if err := rows.Scan(&name, &valsRaw); err != nil {
 			return nil, err
 		}
 		enums = append(enums, Enum{
-			Name:        name,
-			Description: description,
-			Values:      strings.Split(valsRaw, "|"),
+			Name:   name,
+			Values: strings.Split(valsRaw, "|"),
 		})
 	}
 	return enums, nil
 
 }
 
-func getTableNames(ctx context.Context, db *sqrlx.Wrapper, schema string, exclude []string) ([]Table, error) {
-	rows, err := db.QueryRaw(ctx, `SELECT relname,
-	COALESCE(obj_description(CONCAT('public.', relname)::regclass), '')
-	FROM pg_catalog.pg_statio_user_tables WHERE schemaname = $1`, schema)
+func getTableNames(ctx context.Context, db *sqrlx.Wrapper, schema string, filter NameFilter) ([]Table, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'f')`, schema)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	tables := make([]Table, 0)
-rows:
 	for rows.Next() {
 		table := Table{}
-		if err := rows.Scan(&table.Name, &table.Description); err != nil {
+		if err := rows.Scan(&table.Name); err != nil {
 			return nil, err
 		}
-		for _, exc := range exclude {
-			if exc == table.Name {
-				continue rows
-			}
+		if !filter.Includes(table.Name) {
+			continue
 		}
 		tables = append(tables, table)
 	}
 	return tables, nil
 }
 
+// addDescriptions fills in the COMMENT ON text for tables, views,
+// materialized views, columns, constraints, indexes, enums and composite
+// type attributes. Comments are enrichment rather than structure, so a role
+// which can't read them gets a warning and undocumented output instead of an
+// error.
+func addDescriptions(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, views []View, matviews []MaterializedView, enums []Enum, types []CompositeType, report *Report) error {
+	tableDescriptions, err := getTableDescriptions(ctx, db, schema)
+	if err := report.optional("table comments", err); err != nil {
+		return err
+	}
+
+	columnDescriptions, err := getColumnDescriptions(ctx, db, schema)
+	if err := report.optional("column comments", err); err != nil {
+		return err
+	}
+
+	constraintDescriptions, err := getConstraintDescriptions(ctx, db, schema)
+	if err := report.optional("constraint comments", err); err != nil {
+		return err
+	}
+
+	for idx, table := range tables {
+		tables[idx].Description = tableDescriptions[table.Name]
+		for colIdx, col := range table.KeyColumns {
+			table.KeyColumns[colIdx].Description = columnDescriptions[table.Name][col.Name]
+		}
+		for colIdx, col := range table.Columns {
+			table.Columns[colIdx].Description = columnDescriptions[table.Name][col.Name]
+		}
+		// Indexes are relations, so their comments come with the tables'
+		for indexIdx, index := range table.Indexes {
+			table.Indexes[indexIdx].Description = tableDescriptions[index.Name]
+		}
+		for fkIdx, fk := range table.ForeignKeys {
+			table.ForeignKeys[fkIdx].Description = constraintDescriptions[table.Name][fk.Name]
+		}
+		for conIdx, unique := range table.UniqueConstraints {
+			table.UniqueConstraints[conIdx].Description = constraintDescriptions[table.Name][unique.Name]
+		}
+		for conIdx, check := range table.CheckConstraints {
+			table.CheckConstraints[conIdx].Description = constraintDescriptions[table.Name][check.Name]
+		}
+		for conIdx, exclusion := range table.ExclusionConstraints {
+			table.ExclusionConstraints[conIdx].Description = constraintDescriptions[table.Name][exclusion.Name]
+		}
+	}
+	for idx, view := range views {
+		views[idx].Description = tableDescriptions[view.Name]
+		for colIdx, col := range view.Columns {
+			view.Columns[colIdx].Description = columnDescriptions[view.Name][col.Name]
+		}
+	}
+	for idx, view := range matviews {
+		matviews[idx].Description = tableDescriptions[view.Name]
+		for colIdx, col := range view.Columns {
+			view.Columns[colIdx].Description = columnDescriptions[view.Name][col.Name]
+		}
+		for indexIdx, index := range view.Indexes {
+			view.Indexes[indexIdx].Description = tableDescriptions[index.Name]
+		}
+	}
+	for _, compositeType := range types {
+		for attIdx, att := range compositeType.Attributes {
+			compositeType.Attributes[attIdx].Description = columnDescriptions[compositeType.Name][att.Name]
+		}
+	}
+
+	enumDescriptions, err := getEnumDescriptions(ctx, db, schema)
+	if err := report.optional("enum comments", err); err != nil {
+		return err
+	}
+	for idx, enum := range enums {
+		enums[idx].Description = enumDescriptions[enum.Name]
+	}
+
+	return nil
+}
+
+func getTableDescriptions(ctx context.Context, db *sqrlx.Wrapper, schema string) (map[string]string, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, d.description
+	FROM pg_catalog.pg_description d
+	JOIN pg_catalog.pg_class c ON c.oid = d.objoid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE d.classoid = 'pg_catalog.pg_class'::regclass
+	AND d.objsubid = 0
+	AND n.nspname = $1`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	descriptions := map[string]string{}
+	for rows.Next() {
+		var name, description string
+		if err := rows.Scan(&name, &description); err != nil {
+			return nil, err
+		}
+		descriptions[name] = description
+	}
+	return descriptions, rows.Err()
+}
+
+// getConstraintDescriptions reads constraint comments by table, then
+// constraint name
+func getConstraintDescriptions(ctx context.Context, db *sqrlx.Wrapper, schema string) (map[string]map[string]string, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, con.conname, d.description
+	FROM pg_catalog.pg_description d
+	JOIN pg_catalog.pg_constraint con ON con.oid = d.objoid
+	JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE d.classoid = 'pg_catalog.pg_constraint'::regclass
+	AND n.nspname = $1`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	descriptions := map[string]map[string]string{}
+	for rows.Next() {
+		var table, constraint, description string
+		if err := rows.Scan(&table, &constraint, &description); err != nil {
+			return nil, err
+		}
+		if _, ok := descriptions[table]; !ok {
+			descriptions[table] = map[string]string{}
+		}
+		descriptions[table][constraint] = description
+	}
+	return descriptions, rows.Err()
+}
+
+// getColumnDescriptions returns column comments keyed by table then column
+func getColumnDescriptions(ctx context.Context, db *sqrlx.Wrapper, schema string) (map[string]map[string]string, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, a.attname, d.description
+	FROM pg_catalog.pg_description d
+	JOIN pg_catalog.pg_class c ON c.oid = d.objoid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum = d.objsubid
+	WHERE d.classoid = 'pg_catalog.pg_class'::regclass
+	AND d.objsubid > 0
+	AND n.nspname = $1`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	descriptions := map[string]map[string]string{}
+	for rows.Next() {
+		var table, column, description string
+		if err := rows.Scan(&table, &column, &description); err != nil {
+			return nil, err
+		}
+		if _, ok := descriptions[table]; !ok {
+			descriptions[table] = map[string]string{}
+		}
+		descriptions[table][column] = description
+	}
+	return descriptions, rows.Err()
+}
+
+func getEnumDescriptions(ctx context.Context, db *sqrlx.Wrapper, schema string) (map[string]string, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT t.typname, d.description
+	FROM pg_catalog.pg_description d
+	JOIN pg_catalog.pg_type t ON t.oid = d.objoid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+	WHERE d.classoid = 'pg_catalog.pg_type'::regclass
+	AND n.nspname = $1`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	descriptions := map[string]string{}
+	for rows.Next() {
+		var name, description string
+		if err := rows.Scan(&name, &description); err != nil {
+			return nil, err
+		}
+		descriptions[name] = description
+	}
+	return descriptions, rows.Err()
+}
+
+func isPermissionDenied(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// insufficient_privilege
+		return pqErr.Code == "42501"
+	}
+	return false
+}
+
+func warnf(format string, args ...interface{}) {
+	log.Printf("WARNING: "+format, args...)
+}
+
 type Schema struct {
+	// Schemas lists the postgres schemas when several are documented,
+	// names then being qualified with them
+	Schemas []string `json:",omitempty"`
+
 	Tables []Table
-	Enums  []Enum
+	Views  []View `json:",omitempty"`
+
+	MaterializedViews []MaterializedView `json:",omitempty"`
+	Enums             []Enum
+	Domains           []Domain        `json:",omitempty"`
+	Types             []CompositeType `json:",omitempty"`
+	Functions         []Function      `json:",omitempty"`
+	Sequences         []Sequence      `json:",omitempty"`
+	Extensions        []Extension     `json:",omitempty"`
+	Publications      []Publication   `json:",omitempty"`
+	EventTriggers     []EventTrigger  `json:",omitempty"`
 }
 
 type Table struct {
-	Name        string                 `json:"name"`
+	Name string `json:"name"`
+
+	// Schema is the postgres schema, set when several are documented and
+	// Name is qualified with it
+	Schema string `json:"schema,omitempty"`
+
 	Description string                 `json:"description"`
+	PrimaryKey  string                 `json:"primaryKey,omitempty"`
 	KeyColumns  []ColumnDefinition     `json:"keyColumns"`
 	Columns     []ColumnDefinition     `json:"columns"`
 	ForeignKeys []ForeignKeyDefinition `json:"foreignKeys"`
+
+	UniqueConstraints    []UniqueConstraint    `json:"uniqueConstraints,omitempty"`
+	CheckConstraints     []CheckConstraint     `json:"checkConstraints,omitempty"`
+	ExclusionConstraints []ExclusionConstraint `json:"exclusionConstraints,omitempty"`
+
+	// FanOut counts the table's foreign keys, FanIn the foreign keys which
+	// reference it
+	FanOut int `json:"fanOut"`
+	FanIn  int `json:"fanIn"`
+
+	// Foreign is set for foreign tables, whose rows are on another server
+	Foreign *ForeignTable `json:"foreign,omitempty"`
+
+	AccessMethod  string            `json:"accessMethod,omitempty"`
+	StorageParams map[string]string `json:"storageParams,omitempty"`
+
+	// Tablespace is empty for the database's default
+	Tablespace string `json:"tablespace,omitempty"`
+
+	// RLSEnabled is true when row level security applies to the table,
+	// RLSForced when it also applies to the table's owner
+	RLSEnabled bool     `json:"rlsEnabled"`
+	RLSForced  bool     `json:"rlsForced"`
+	Policies   []Policy `json:"policies,omitempty"`
+
+	Indexes  []Index   `json:"indexes"`
+	Triggers []Trigger `json:"triggers,omitempty"`
+	Grants   []Grant   `json:"grants,omitempty"`
+	Rules    []Rule    `json:"rules,omitempty"`
+
+	// Size is only read with -sizes
+	Size *TableSize `json:"size,omitempty"`
+
+	// Maintenance is only read with -maintenance
+	Maintenance *Maintenance `json:"maintenance,omitempty"`
+
+	// Sample is only read with -sample
+	Sample *SampleRows `json:"sample,omitempty"`
+
+	// Publications are the names of the publications streaming the table
+	Publications []string `json:"publications,omitempty"`
+
+	// UsedBy are the views and materialized views selecting from the table
+	UsedBy []string `json:"usedBy,omitempty"`
+
+	// PartitionKey is the strategy and key of a partitioned table, like
+	// RANGE (created_at). Partitions have PartitionOf set to their parent,
+	// and PartitionBound to the values they hold.
+	PartitionKey   string   `json:"partitionKey,omitempty"`
+	Partitions     []string `json:"partitions,omitempty"`
+	PartitionOf    string   `json:"partitionOf,omitempty"`
+	PartitionBound string   `json:"partitionBound,omitempty"`
+
+	Polymorphic []PolymorphicAssociation `json:"polymorphic,omitempty"`
+}
+
+// allColumns returns the key columns followed by the rest
+func (t Table) allColumns() []ColumnDefinition {
+	return append(append([]ColumnDefinition{}, t.KeyColumns...), t.Columns...)
+}
+
+// keyCount is the number of primary key columns, which -column-order can
+// move out of KeyColumns
+func (t Table) keyCount() int {
+	count := 0
+	for _, col := range t.allColumns() {
+		if col.IsKey {
+			count++
+		}
+	}
+	return count
 }
 
 type ColumnDefinition struct {
@@ -286,30 +1422,103 @@ type ColumnDefinition struct {
 	CustomType  bool   `sql:"custom_type" json:"custom"`
 	Description string `sql:"description" json:"description"`
 	IsNullable  bool   `sql:"is_nullable" json:"nullable"`
+
+	// Default is the default expression, empty when there isn't one
+	Default string `sql:"column_default" json:"default,omitempty"`
+
+	// Identity is ALWAYS or BY DEFAULT for identity columns
+	Identity string `sql:"identity" json:"identity,omitempty"`
+
+	// Generated is the expression of a GENERATED ALWAYS AS ... STORED column
+	Generated string `sql:"generated" json:"generated,omitempty"`
+
+	// Sequence is the sequence the column owns, as a serial or identity
+	Sequence string `json:"sequence,omitempty"`
+
+	// Domain is the domain the column was declared with, DataType being
+	// its base type
+	Domain string `sql:"domain" json:"domain,omitempty"`
+
+	// IsArray is set for arrays, which have the DataType element_type[]
+	IsArray bool `sql:"is_array" json:"array,omitempty"`
+
+	// Collation is set when it isn't the database's default
+	Collation string `sql:"collation" json:"collation,omitempty"`
+
+	// Stats are only read with -stats, and only for analyzed tables
+	Stats *ColumnStats `json:"stats,omitempty"`
+
+	// Profile is only read for the columns -profile selects
+	Profile *ColumnProfile `json:"profile,omitempty"`
+
+	// InheritedFrom is the table.column a view column's Description is
+	// from, with -inherit-view-comments
+	InheritedFrom string `json:"inheritedFrom,omitempty"`
+
+	// Checks are the definitions of the CHECK constraints on this column
+	// alone, which are also in the table's CheckConstraints
+	Checks []string `json:"-"`
+
+	// Ordinal is the column's position in the table definition
+	Ordinal int  `sql:"ordinal_position" json:"ordinal"`
+	IsKey   bool `json:"key"`
+}
+
+// ElementType is the type of an array's elements, or the DataType of
+// anything else
+func (col ColumnDefinition) ElementType() string {
+	if col.IsArray {
+		return strings.TrimSuffix(col.DataType, "[]")
+	}
+	return col.DataType
 }
 
 type Enum struct {
 	Name        string
 	Description string
 	Values      []string
+
+	// Transitions are declared in the enum's comment when it models a
+	// state machine
+	Transitions []Transition `json:",omitempty"`
+
+	// UsedBy are the table.column pairs with the enum as their type
+	UsedBy []string `json:",omitempty"`
 }
 
+// arrayDataType names an array column's type from its element type, which
+// information_schema only gives as the array's udt_name, like _int4
+const arrayDataType = `(SELECT CASE
+	WHEN et.typnamespace <> 'pg_catalog'::regnamespace THEN et.typname::text
+	ELSE pg_catalog.format_type(et.oid, NULL)
+	END || '[]'
+	FROM pg_catalog.pg_type at
+	JOIN pg_catalog.pg_namespace an ON an.oid = at.typnamespace
+	JOIN pg_catalog.pg_type et ON et.oid = at.typelem
+	WHERE at.typname = c.udt_name AND an.nspname = c.udt_schema)`
+
 func getColumns(ctx context.Context, db *sqrlx.Wrapper, schema string, tableName string) ([]ColumnDefinition, error) {
 
 	builder := sq.Select(
 		"c.column_name",
+		"c.ordinal_position",
 		"CASE WHEN c.is_nullable = 'NO' THEN false ELSE true END AS is_nullable",
-		"CASE WHEN data_type = 'USER-DEFINED' THEN true ELSE false END AS custom_type",
-		"COALESCE(pgd.description, '') AS description",
-	).From("pg_catalog.pg_statio_all_tables AS st").
-		Join("pg_catalog.pg_description pgd on (pgd.objoid=st.relid)").
-		RightJoin("information_schema.columns c on (pgd.objsubid=c.ordinal_position and c.table_schema=st.schemaname and c.table_name=st.relname)").
+		// Array types live in the schema of their element type
+		"data_type = 'USER-DEFINED' OR (data_type = 'ARRAY' AND c.udt_schema <> 'pg_catalog') AS custom_type",
+		"data_type = 'ARRAY' AS is_array",
+		"COALESCE(c.collation_name::text, '') AS collation",
+		"COALESCE(c.column_default, '') AS column_default",
+		"CASE WHEN c.is_identity = 'YES' THEN c.identity_generation ELSE '' END AS identity",
+		"CASE WHEN c.is_generated = 'ALWAYS' THEN c.generation_expression ELSE '' END AS generated",
+		"COALESCE(c.domain_name::text, '') AS domain",
+	).From("information_schema.columns c").
 		Where("c.table_schema = ?", schema).
 		Where("c.table_name = ?", tableName).
 		OrderBy("ordinal_position ASC")
 
 	if stmt, args, err := sq.Case("data_type").
 		When("'USER-DEFINED'", "udt_name").
+		When("'ARRAY'", arrayDataType).
 		When("'numeric'", "CONCAT('Number(', numeric_precision, ',', numeric_scale,')')").
 		When("'character'", "CONCAT('Char(', character_maximum_length, ')')").
 		When("'timestamp with time zone'", "'timestamp'").
@@ -338,6 +1547,7 @@ func getColumns(ctx context.Context, db *sqrlx.Wrapper, schema string, tableName
 }
 
 type ColumnIdentity struct {
+	Schema string `json:"schema,omitempty"`
 	Table  string `json:"table"`
 	Column string `json:"column"`
 }
@@ -347,6 +1557,28 @@ type ConstraintDefinition struct {
 	ForeignColumns []ColumnIdentity `json:"foreign_columns"`
 	ConstraintName string           `json:"constraint_name"`
 	ConstraintType string           `json:"constraint_type"`
+
+	// Definition is the expression of a CHECK constraint, and CheckColumns
+	// the columns it refers to
+	Definition   string   `json:"definition"`
+	CheckColumns []string `json:"check_columns"`
+
+	// OnDelete and OnUpdate are a FOREIGN KEY's referential actions
+	OnDelete string `json:"on_delete"`
+	OnUpdate string `json:"on_update"`
+
+	Deferrable        bool `json:"deferrable"`
+	InitiallyDeferred bool `json:"initially_deferred"`
+}
+
+// referentialAction spells out a pg_constraint action code
+func referentialAction(column string) string {
+	return `CASE ` + column + `
+	WHEN 'r' THEN 'RESTRICT'
+	WHEN 'c' THEN 'CASCADE'
+	WHEN 'n' THEN 'SET NULL'
+	WHEN 'd' THEN 'SET DEFAULT'
+	ELSE 'NO ACTION' END`
 }
 
 func getConstraints(ctx context.Context, db *sqrlx.Wrapper, schema string, tableName string) ([]ConstraintDefinition, error) {
@@ -356,22 +1588,42 @@ SELECT
 kcu_sub.columns AS local_columns,
 ccu_sub.columns AS foreign_columns,
 tc.constraint_name,
-tc.constraint_type 
+tc.constraint_type,
+chk.definition,
+chk.check_columns,
+ccu_sub.on_delete,
+ccu_sub.on_update,
+tc.is_deferrable = 'YES' AS deferrable,
+tc.initially_deferred = 'YES' AS initially_deferred
 FROM 
 information_schema.table_constraints tc
 LEFT JOIN (
+        -- In the order of the local columns, which information_schema
+        -- doesn't give for the referenced side
         SELECT
-        cu.constraint_name,
-        cu.constraint_schema,
+        pc.conname AS constraint_name,
+        pn.nspname AS constraint_schema,
+        pr.relname AS table_name,
         array_to_json(array_agg(JSON_BUILD_OBJECT(
-                        'table', cu.table_name::text,
-                        'column', cu.column_name::text
-        ))) AS columns 
-        FROM information_schema.constraint_column_usage cu
-        GROUP BY cu.constraint_name, cu.constraint_schema
+                        'schema', fn.nspname::text,
+                        'table', fr.relname::text,
+                        'column', fa.attname::text
+        ) ORDER BY k.ord)) AS columns,
+        `+referentialAction("pc.confdeltype")+` AS on_delete,
+        `+referentialAction("pc.confupdtype")+` AS on_update
+        FROM pg_catalog.pg_constraint pc
+        JOIN pg_catalog.pg_namespace pn ON pn.oid = pc.connamespace
+        JOIN pg_catalog.pg_class pr ON pr.oid = pc.conrelid
+        JOIN pg_catalog.pg_class fr ON fr.oid = pc.confrelid
+        JOIN pg_catalog.pg_namespace fn ON fn.oid = fr.relnamespace
+        CROSS JOIN LATERAL unnest(pc.confkey) WITH ORDINALITY AS k(attnum, ord)
+        JOIN pg_catalog.pg_attribute fa ON fa.attrelid = pc.confrelid AND fa.attnum = k.attnum
+        WHERE pc.contype = 'f'
+        GROUP BY pc.conname, pn.nspname, pr.relname, pc.confdeltype, pc.confupdtype
 ) AS ccu_sub ON
 ccu_sub.constraint_name = tc.constraint_name 
 AND ccu_sub.constraint_schema = tc.constraint_schema
+AND ccu_sub.table_name = tc.table_name
 AND tc.constraint_type = 'FOREIGN KEY'
 LEFT JOIN (
         SELECT
@@ -382,12 +1634,37 @@ LEFT JOIN (
         array_to_json(array_agg(JSON_BUILD_OBJECT(
                         'table', cu.table_name::text,
                         'column', cu.column_name::text
-        ))) AS columns
+        ) ORDER BY cu.ordinal_position)) AS columns
         FROM information_schema.key_column_usage cu
         GROUP BY cu.constraint_name, cu.constraint_schema, cu.table_name, cu.table_schema
-) AS kcu_sub ON kcu_sub.constraint_name = tc.constraint_name AND kcu_sub.constraint_schema = tc.constraint_schema
-WHERE tc.constraint_type IN ('FOREIGN KEY','PRIMARY KEY')
-AND kcu_sub.table_schema = $1 AND kcu_sub.table_name = $2) AS root;`,
+) AS kcu_sub ON kcu_sub.constraint_name = tc.constraint_name
+AND kcu_sub.constraint_schema = tc.constraint_schema
+AND kcu_sub.table_name = tc.table_name
+AND kcu_sub.table_schema = tc.table_schema
+LEFT JOIN (
+        SELECT
+        pc.conname,
+        pn.nspname,
+        pr.relname,
+        pg_catalog.pg_get_constraintdef(pc.oid, true) AS definition,
+        ARRAY(
+                SELECT a.attname::text
+                FROM pg_catalog.pg_attribute a
+                WHERE a.attrelid = pc.conrelid AND a.attnum = ANY(pc.conkey)
+                ORDER BY a.attnum
+        ) AS check_columns
+        FROM pg_catalog.pg_constraint pc
+        JOIN pg_catalog.pg_namespace pn ON pn.oid = pc.connamespace
+        JOIN pg_catalog.pg_class pr ON pr.oid = pc.conrelid
+        WHERE pc.contype = 'c'
+) AS chk ON chk.conname = tc.constraint_name
+AND chk.nspname = tc.constraint_schema
+AND chk.relname = tc.table_name
+AND tc.constraint_type = 'CHECK'
+WHERE tc.constraint_type IN ('FOREIGN KEY','PRIMARY KEY','UNIQUE','CHECK')
+-- information_schema reports NOT NULL columns as CHECK constraints too
+AND (tc.constraint_type <> 'CHECK' OR chk.definition IS NOT NULL)
+AND tc.table_schema = $1 AND tc.table_name = $2) AS root;`,
 		schema,
 		tableName,
 	)
@@ -426,42 +1703,161 @@ func (c *PUMLWriter) Printf(str string, p ...interface{}) {
 	c.data = c.data + fmt.Sprintf(str, p...)
 }
 
-func (c *PUMLWriter) Column(column ColumnDefinition) {
+// Column renders one column, markKey flags key columns which aren't in
+// the section above the separator
+func (c *PUMLWriter) Column(column ColumnDefinition, markKey bool) {
 	prefix := map[bool]string{true: "", false: "* "}[column.IsNullable]
+	suffix := map[bool]string{true: " <<PK>>", false: ""}[markKey]
+	if column.Generated != "" {
+		suffix += " <<generated>>"
+	}
+	for _, check := range column.Checks {
+		suffix += " <<" + check + ">>"
+	}
 	if c.IncludeDataTypes {
-		c.Printf("  %s%s: %s\n", prefix, column.Name, column.DataType)
+		c.Printf("  %s%s: %s%s\n", prefix, column.Name, column.DataType, suffix)
 	} else {
-		c.Printf("  %s%s\n", prefix, column.Name)
+		c.Printf("  %s%s%s\n", prefix, column.Name, suffix)
 	}
 }
 
 func (c *PUMLWriter) Table(table Table) {
-	c.Printf("entity %s {\n", table.Name)
+	if table.Foreign != nil {
+		c.Printf("entity %s <<foreign>> {\n", table.Name)
+	} else {
+		c.Printf("entity %s {\n", table.Name)
+	}
 	for _, column := range table.KeyColumns {
-		c.Column(column)
+		c.Column(column, false)
 	}
 	c.Println("--")
 	for _, column := range table.Columns {
-		c.Column(column)
+		c.Column(column, column.IsKey)
 	}
 	c.Println("}")
+
+	if c.IncludeDescriptions && table.Description != "" {
+		c.Printf("note top of %s\n", table.Name)
+		for _, line := range wrapText(table.Description, c.DescriptionWidth) {
+			c.Printf("  %s\n", line)
+		}
+		c.Println("end note")
+	}
+}
+
+// wrapText splits text into lines of at most width characters, breaking
+// between words and keeping the text's own line breaks. Words longer than
+// width get a line to themselves.
+func wrapText(text string, width int) []string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && width > 0 && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Relationship draws a foreign key from the named table or view, dashed
+// when it is logical
+func (c *PUMLWriter) Relationship(name string, fk ForeignKeyDefinition) {
+	line := "-"
+	if fk.Logical {
+		line = "."
+	}
+	// Keys into other schemas are coloured
+	if fk.IsCrossSchema() {
+		line += "[" + crossSchemaColor + "]" + line
+	} else {
+		line += line
+	}
+	if fk.Logical {
+		c.Printf("%s }|%s|| %s\n", name, line, fk.RefTable)
+		return
+	}
+	if actions := fk.Actions(); actions != "" {
+		c.Printf("%s }|%s|| %s : %s\n", name, line, fk.RefTable, actions)
+		return
+	}
+	c.Printf("%s }|%s|| %s\n", name, line, fk.RefTable)
 }
 
 func (c *PUMLWriter) Schema(schema *Schema) {
 	c.Println("@startuml")
+	c.Printf("%s", c.Header)
+
+	// Several schemas are packages, and the dots in qualified names
+	// mustn't make more
+	packages := []string{""}
+	if len(schema.Schemas) > 1 {
+		c.Println("set namespaceSeparator none")
+		packages = schema.Schemas
+	}
 
 	if c.IncludeColumns {
-		for _, table := range schema.Tables {
-			c.Table(table)
+		for _, pkg := range packages {
+			if pkg != "" {
+				c.Printf("package %s {\n", pkg)
+			}
+			for _, table := range schema.Tables {
+				if pkg == "" || schemaOf(table.Name, packages) == pkg {
+					c.Table(table)
+				}
+			}
+			for _, view := range schema.Views {
+				if pkg == "" || schemaOf(view.Name, packages) == pkg {
+					c.View(view, "view")
+				}
+			}
+			for _, view := range schema.MaterializedViews {
+				if pkg == "" || schemaOf(view.Name, packages) == pkg {
+					c.View(view.View, "materialized")
+				}
+			}
+			if pkg != "" {
+				c.Println("}")
+			}
 		}
 	}
 
 	for _, table := range schema.Tables {
 		for _, fk := range table.ForeignKeys {
-			c.Printf("%s }|--|| %s\n", table.Name, fk.RefTable)
+			c.Relationship(table.Name, fk)
+		}
+		// Dashed, as there is no constraint enforcing these
+		for _, assoc := range table.Polymorphic {
+			if len(assoc.Targets) == 0 {
+				c.Printf("note right of %s : %s is polymorphic\n", table.Name, assoc.Name)
+				continue
+			}
+			for _, target := range assoc.Targets {
+				c.Printf("%s }o..o| %s : %s\n", table.Name, target, assoc.Name)
+			}
+		}
+	}
+
+	// Views only have logical keys
+	for _, view := range schema.Views {
+		for _, fk := range view.ForeignKeys {
+			c.Relationship(view.Name, fk)
+		}
+	}
+	for _, view := range schema.MaterializedViews {
+		for _, fk := range view.ForeignKeys {
+			c.Relationship(view.Name, fk)
 		}
 	}
 
+	c.Printf("%s", c.Footer)
 	c.Println("@enduml")
 
 }
@@ -469,6 +1865,15 @@ func (c *PUMLWriter) Schema(schema *Schema) {
 type PUMLOptions struct {
 	IncludeColumns   bool
 	IncludeDataTypes bool
+
+	// IncludeDescriptions attaches table comments as notes, wrapped to
+	// DescriptionWidth characters
+	IncludeDescriptions bool
+	DescriptionWidth    int
+
+	// Header and Footer are written inside @startuml / @enduml
+	Header string
+	Footer string
 }
 
 func pumlDump(schema *Schema, writer io.Writer, options PUMLOptions) {
@@ -483,77 +1888,546 @@ func pumlDump(schema *Schema, writer io.Writer, options PUMLOptions) {
 
 }
 
-func mdDump(schema *Schema, w io.Writer) error {
+// MarkdownOptions are the optional sections of the markdown output
+type MarkdownOptions struct {
+	// NamingConventions are summarised in a glossary when set
+	NamingConventions []NamingConvention
+
+	// TopHubs lists this many of the most referenced tables
+	TopHubs int
+
+	// LinkTypes are always linked to their definitions, NoLinkTypes never
+	// are, overriding the CustomType detection
+	LinkTypes   []string
+	NoLinkTypes []string
+
+	// Template is a file or directory of templates replacing the built in
+	// ones, see parseCustomTemplates
+	Template string
+
+	// HTMLRawDescriptions leaves descriptions in the HTML outputs
+	// unsanitized, for schemas whose comments are trusted
+	HTMLRawDescriptions bool
+
+	// Header and Footer are written around each document, and each page of
+	// the multi-page outputs
+	Header string
+	Footer string
+}
+
+// linkable decides whether a column's type links to a definition
+func (o MarkdownOptions) linkable(col ColumnDefinition) bool {
+	for _, name := range o.NoLinkTypes {
+		if name == col.ElementType() {
+			return false
+		}
+	}
+	for _, name := range o.LinkTypes {
+		if name == col.ElementType() {
+			return true
+		}
+	}
+	return col.CustomType
+}
+
+func mdDump(schema *Schema, w io.Writer, options MarkdownOptions) error {
+
+	tpl, err := markdownTemplate(options)
+	if err != nil {
+		return err
+	}
+
+	data := execData{
+		Data: schema,
+	}
+	if options.NamingConventions != nil {
+		data.Conventions = analyseConventions(schema, options.NamingConventions)
+	}
+	if options.TopHubs > 0 {
+		data.Hubs = topHubs(schema, options.TopHubs)
+	}
+	data.Schemas = tablesBySchema(schema)
+	data.Sizes = largestTables(schema)
+	data.Profiles = profiledColumns(schema)
 
-	tpl, err := template.New("markdown.md").Funcs(template.FuncMap{
+	return tpl.Execute(w, data)
+}
+
+// markdownTemplate parses the markdown template. Besides the whole document
+// it defines "table" and "enum" for outputs with a page per object, which
+// replace typeHref and tableHref to link across pages.
+func markdownTemplate(options MarkdownOptions) (*template.Template, error) {
+	tpl, err := template.New("markdown.md").Funcs(templateFuncs()).Funcs(template.FuncMap{
 		"mdescape": func(val string) string {
 			val = strings.ReplaceAll(val, "\n\n", "<br>")
 			val = strings.ReplaceAll(val, "\n", " ")
 			return val
 		},
-		"anchor": func(val string) string {
-			return strings.ToLower(strings.ReplaceAll(val, "_", "-"))
+		"join":         strings.Join,
+		"bytes":        byteSize,
+		"linkable":     options.linkable,
+		"anchor":       anchor,
+		"snakeToTitle": snakeToTitle,
+		"typeHref": func(dataType string) string {
+			return "#" + anchor(dataType)
 		},
-		"snakeToTitle": func(val string) string {
-			words := strings.Split(val, "_")
-			for idx, word := range words {
-				if len(word) >= 2 {
-					words[idx] = strings.ToTitle(word[0:1]) + word[1:]
-				}
-			}
-			return strings.Join(words, " ")
+		"tableHref": func(name string) string {
+			return "#" + anchor(name)
 		},
 	}).Parse(defaultTemplate)
-
-	if err != nil {
-		return err
+	if err != nil || options.Template == "" {
+		return tpl, err
 	}
+	return parseCustomTemplates(tpl, options.Template)
+}
 
-	return tpl.Execute(w, execData{
-		Data: schema,
-	})
+// anchor is the fragment markdown renderers give a heading
+func anchor(val string) string {
+	// Markdown renderers drop the dot of qualified names from heading ids
+	return strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(val, "_", "-"), ".", ""))
+}
+
+func snakeToTitle(val string) string {
+	words := strings.Split(val, "_")
+	for idx, word := range words {
+		if len(word) >= 2 {
+			words[idx] = strings.ToTitle(word[0:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
 }
 
 type execData struct {
-	Data interface{}
+	Data        interface{}
+	Conventions []ConventionUsage
+	Hubs        []Table
+
+	// Sizes are the tables with a size, largest first
+	Sizes []Table
+
+	// Profiles are the profiled columns, for an appendix
+	Profiles []ProfiledColumn
+
+	// Schemas sections the tables by schema when several are documented
+	Schemas []SchemaTables
 }
 
 var defaultTemplate = `
+{{- if .Hubs }}
+Most Referenced Tables
+======================
+
+| Table | Referenced by | References |
+|-------|---------------|------------|
+{{ range .Hubs -}}
+| [{{ .Name }}](#{{ anchor .Name }}) | {{ .FanIn }} | {{ .FanOut }} |
+{{ end }}
+{{ end }}
+{{- if .Sizes }}
+Table Sizes
+===========
+
+| Table | Rows | Table | Indexes | TOAST | Total |
+|-------|------|-------|---------|-------|-------|
+{{ range .Sizes -}}
+| [{{ .Name }}](#{{ anchor .Name }}) | {{ .Size.RowEstimate }} | {{ bytes .Size.Table }} | {{ bytes .Size.Indexes }} | {{ bytes .Size.Toast }} | {{ bytes .Size.Total }} |
+{{ end }}
+{{ end }}
+{{- if .Schemas }}
+{{- range .Schemas }}
+Schema {{ .Name }}
+======
+
+{{ range .Tables }}{{ template "table" . }}{{ end }}
+{{ end }}
+{{- else }}
 Tables
 ======
 
-{{ range .Data.Tables }}
+{{ range .Data.Tables }}{{ template "table" . }}{{ end }}
+{{- end }}
+{{- if .Data.Views }}
+
+Views
+=====
+
+{{ range .Data.Views }}{{ template "view" . }}{{ end }}
+{{- end }}
+{{- if .Data.MaterializedViews }}
+
+Materialized Views
+==================
+
+{{ range .Data.MaterializedViews }}{{ template "matview" . }}{{ end }}
+{{- end }}
+
+
+Enums
+=====
+
+{{ range .Data.Enums }}{{ template "enum" . }}{{ end }}
+{{- if .Data.Domains }}
+
+Domains
+=======
+
+{{ range .Data.Domains }}{{ template "domain" . }}{{ end }}
+{{- end }}
+{{- if .Data.Types }}
+
+Types
+=====
+
+{{ range .Data.Types }}{{ template "composite" . }}{{ end }}
+{{- end }}
+{{- if .Data.Functions }}
+
+Functions
+=========
+
+{{ range .Data.Functions }}{{ template "function" . }}{{ end }}
+{{- end }}
+{{- if .Data.Sequences }}
+
+Sequences
+=========
+
+| Sequence | Owned By | Type | Start | Increment | Last Value |
+|----------|----------|------|-------|-----------|------------|
+{{ range .Data.Sequences -}}
+| {{ .Name }} | {{ if .OwnedBy }}{{ .OwnedBy }}{{ if .Identity }} (identity){{ end }}{{ end }} | {{ .DataType }} | {{ .Start }} | {{ .Increment }} | {{ if .LastValue }}{{ .LastValue }}{{ end }} |
+{{ end }}
+{{- end }}
+{{- if .Data.Extensions }}
+
+Extensions
+==========
+
+| Extension | Version | Schema | Description |
+|-----------|---------|--------|-------------|
+{{ range .Data.Extensions -}}
+| {{ .Name }} | {{ .Version }} | {{ .Schema }} | {{ mdescape .Description }} |
+{{ end }}
+{{- end }}
+{{- if .Data.Publications }}
+
+Replication
+===========
+
+| Publication | Operations | Tables |
+|-------------|------------|--------|
+{{ range .Data.Publications -}}
+| {{ .Name }} | {{ join .Operations ", " }} | {{ if .AllTables }}all tables{{ else }}{{ join .Tables ", " }}{{ end }} |
+{{ end }}
+{{- end }}
+{{- if .Data.EventTriggers }}
+
+Event Triggers
+==============
+
+| Trigger | Event | Commands | Function | Enabled |
+|---------|-------|----------|----------|---------|
+{{ range .Data.EventTriggers -}}
+| {{ .Name }} | {{ .Event }} | {{ if .Tags }}{{ join .Tags ", " }}{{ else }}all{{ end }} | {{ .Function }} | {{ .Enabled }} |
+{{ end }}
+{{- end }}
+{{- if .Conventions }}
+
+Naming Conventions
+==================
+
+| Pattern | Meaning | Columns |
+|---------|---------|---------|
+{{ range .Conventions -}}
+| ` + "`{{ .Pattern }}`" + ` | {{ .Meaning }} | {{ join .Examples ", " }}{{ if .More }} and {{ .More }} more{{ end }} |
+{{ end }}
+{{- end }}
+{{- if .Profiles }}
+
+Profiling
+=========
+
+| Column | Rows | Nulls | Distinct | Min | Max |
+|--------|------|-------|----------|-----|-----|
+{{ range .Profiles -}}
+| [{{ .Table }}](#{{ anchor .Table }}).{{ .Column }} | {{ .Profile.Rows }} | {{ .Profile.Nulls }} | {{ .Profile.Distinct }} | {{ mdescape .Profile.Min }} | {{ mdescape .Profile.Max }} |
+{{ end }}
+{{- end }}
+{{- define "table" }}
+{{ snakeToTitle .Name }}
+-----------
+{{ if or .FanIn .FanOut }}
+Referenced by {{ .FanIn }}, references {{ .FanOut }}
+{{ end }}
+{{ .Description }}
+{{ if .PartitionKey }}
+Partitioned by ` + "`{{ .PartitionKey }}`" + `{{ if .Partitions }} into {{ join .Partitions ", " }}{{ end }}
+{{ end }}
+{{- if .PartitionOf }}
+Partition of {{ .PartitionOf }} ` + "`{{ .PartitionBound }}`" + `
+{{ end }}
+{{- if .Publications }}
+Streamed by {{ join .Publications ", " }}
+{{ end }}
+{{- if .UsedBy }}
+Used by {{ join .UsedBy ", " }}
+{{ end }}
+{{- with .Foreign }}
+Foreign table on server ` + "`{{ .Server }}`" + ` ({{ .Wrapper }}){{ range $name, $value := .Options }}, {{ $name }} ` + "`{{ $value }}`" + `{{ end }}
+{{ end }}
+| Name | Type | Default | Description |
+|------|------|---------|-------------|
+{{ range .KeyColumns -}}
+| {{ .Name }} (KEY)| {{ template "type" . }} | {{ template "default" . }} | {{ mdescape .Description}}{{ template "checks" . }} |
+{{ end -}}
+{{ range .Columns -}}
+| {{ .Name }}{{ if .IsKey }} (KEY){{ end }} | {{ template "type" . }} | {{ template "default" . }} | {{ mdescape .Description}}{{ template "checks" . }} |
+{{ end }}
+
+{{ range .ForeignKeys }}
+{{ .Name }}{{ if .Logical }} (logical){{ end }}{{ with .Actions }} ({{ . }}){{ end }}{{ with .Deferral }} ({{ . }}){{ end }}{{ with .Description }}: {{ mdescape . }}{{ end }}
+{{ end }}
+{{ range .UniqueConstraints }}
+{{ .Name }} (unique on {{ join .Columns ", " }}){{ with .Deferral }} ({{ . }}){{ end }}{{ with .Description }}: {{ mdescape . }}{{ end }}
+{{ end }}
+{{ range .Polymorphic }}
+{{ .Name }} (polymorphic on {{ .TypeColumn }}, {{ .IDColumn }}{{ if .Targets }}: {{ join .Targets ", " }}{{ end }})
+{{ end }}
+{{- if .RLSEnabled }}
+
+### Security Policies
+
+Row level security is enabled{{ if .RLSForced }} and forced for the table owner{{ end }}.
+{{ if .DeniesAll }}
+**Warning:** there are no policies, so every row is hidden.
+{{ else }}
+| Policy | Command | Roles | Type | Using | With Check |
+|--------|---------|-------|------|-------|------------|
+{{ range .Policies -}}
+| {{ .Name }} | {{ .Command }} | {{ join .Roles ", " }} | {{ if .Permissive }}permissive{{ else }}restrictive{{ end }} | {{ if .Using }}` + "`{{ mdescape .Using }}`" + `{{ end }} | {{ if .WithCheck }}` + "`{{ mdescape .WithCheck }}`" + `{{ end }} |
+{{ end }}
+{{- end }}
+{{- end }}
+{{- with .TableChecks }}
+
+### Checks
+
+| Constraint | Definition | Description |
+|------------|------------|-------------|
+{{ range . -}}
+| {{ .Name }} | ` + "`{{ mdescape .Definition }}`" + ` | {{ mdescape .Description }} |
+{{ end }}
+{{- end }}
+{{- if .ExclusionConstraints }}
+
+### Exclusions
+
+| Constraint | Using | Elements | Where | Description |
+|------------|-------|----------|-------|-------------|
+{{ range .ExclusionConstraints -}}
+| {{ .Name }}{{ with .Deferral }} ({{ . }}){{ end }} | {{ .Method }} | ` + "`{{ mdescape (join .Elements \", \") }}`" + ` | {{ if .Predicate }}` + "`{{ mdescape .Predicate }}`" + `{{ end }} | {{ mdescape .Description }} |
+{{ end }}
+{{- end }}
+{{- if .Indexes }}
+
+### Indexes
+{{ template "indexes" .Indexes }}
+{{- end }}
+{{- if .Triggers }}
+
+### Triggers
+
+| Trigger | When | Events | Level | Function | Enabled |
+|---------|------|--------|-------|----------|---------|
+{{ range .Triggers -}}
+| {{ .Name }} | {{ .Timing }} | {{ join .Events ", " }} | {{ .Level }} | {{ .Function }} | {{ .Enabled }} |
+{{ end }}
+{{- end }}
+{{- if .Rules }}
+
+### Rules
+{{ range .Rules }}
+` + "```sql" + `
+{{ .Definition }}
+` + "```" + `
+{{ end }}
+{{- end }}
+{{- if .Grants }}
+
+### Privileges
+
+| Grantee | Privilege | Columns | Grantable |
+|---------|-----------|---------|-----------|
+{{ range .Grants -}}
+| {{ .Grantee }} | {{ .Privilege }} | {{ if .Columns }}{{ join .Columns ", " }}{{ else }}all{{ end }} | {{ if .Grantable }}yes{{ end }} |
+{{ end }}
+{{- end }}
+{{- if .HasStats }}
+
+### Statistics
+
+| Column | Nulls | Distinct | Most Common |
+|--------|-------|----------|-------------|
+{{ range .KeyColumns }}{{ template "stats" . }}{{ end -}}
+{{ range .Columns }}{{ template "stats" . }}{{ end }}
+{{- end }}
+{{- with .Maintenance }}
+
+### Maintenance
+
+| | Vacuum | Analyze |
+|-|--------|---------|
+| Manual | {{ template "timestamp" .LastVacuum }} | {{ template "timestamp" .LastAnalyze }} |
+| Autovacuum | {{ template "timestamp" .LastAutovacuum }} | {{ template "timestamp" .LastAutoanalyze }} |
+{{- end }}
+{{- with .Sample }}
+
+<details>
+<summary>Sample rows</summary>
+
+|{{ range .Columns }} {{ . }} |{{ end }}
+|{{ range .Columns }}---|{{ end }}
+{{ range .Formatted -}}
+|{{ range . }} {{ mdescape . }} |{{ end }}
+{{ end }}
+</details>
+{{- end }}
+{{- if .HasCustomStorage }}
+
+### Storage
+
+{{ if .AccessMethod }}- Access method: {{ .AccessMethod }}
+{{ end -}}
+{{ if .Tablespace }}- Tablespace: {{ .Tablespace }}
+{{ end -}}
+{{ range $name, $value := .StorageParams -}}
+- {{ $name }}: {{ $value }}
+{{ end }}
+{{- end }}
+{{ end }}
+{{- define "view" }}
 {{ snakeToTitle .Name }}
 -----------
 
 {{ .Description }}
+{{ if .DependsOn }}
+Selects from {{ join .DependsOn ", " }}
+{{ end }}
+| Name | Type | Description |
+|------|------|-------------|
+{{ range .Columns -}}
+| {{ .Name }}{{ if .IsKey }} (KEY){{ end }} | {{ template "type" . }} | {{ mdescape .Description}}{{ with .InheritedFrom }} (inherited from {{ . }}){{ end }} |
+{{ end }}
+{{- range .ForeignKeys }}
+{{ .Name }} (logical)
+{{ end }}
+` + "```sql" + `
+{{ .Definition }}
+` + "```" + `
+{{ end }}
+{{- define "matview" }}
+{{ snakeToTitle .Name }}
+-----------
 
+{{ .Description }}
+{{ if not .Populated }}
+**Not populated**, refresh before querying.
+{{ else if .LastAnalyzed }}
+Last analyzed {{ .LastAnalyzed.Format "2006-01-02 15:04" }}, usually just after a refresh.
+{{ end }}
+{{- if .DependsOn }}
+Selects from {{ join .DependsOn ", " }}
+{{ end }}
 | Name | Type | Description |
 |------|------|-------------|
-{{ range .KeyColumns -}}
-| {{ .Name }} (KEY)| {{ if .CustomType }}[{{.DataType}}](#{{anchor .DataType}}){{ else }}{{.DataType}}{{ end }} | {{ mdescape .Description}} |
-{{ end -}}
 {{ range .Columns -}}
-| {{ .Name }} | {{ if .CustomType }}[{{.DataType}}](#{{anchor .DataType}}){{ else }}{{.DataType}}{{ end }} | {{ mdescape .Description}} |
+| {{ .Name }}{{ if .IsKey }} (KEY){{ end }} | {{ template "type" . }} | {{ mdescape .Description}}{{ with .InheritedFrom }} (inherited from {{ . }}){{ end }} |
+{{ end }}
+{{- range .ForeignKeys }}
+{{ .Name }} (logical)
+{{ end }}
+{{- if .Indexes }}{{ template "indexes" .Indexes }}{{ end }}
+` + "```sql" + `
+{{ .Definition }}
+` + "```" + `
 {{ end }}
+{{- define "type" }}
+{{- if .Domain }}[{{ .Domain }}]({{ typeHref .Domain }}){{ else if linkable . }}[{{ .DataType }}]({{ typeHref .ElementType }}){{ else }}{{ .DataType }}{{ end }}
+{{- with .Collation }} (collation {{ . }}){{ end }}
+{{- end }}
+{{- define "domain" }}
+{{ snakeToTitle .Name }}
+-------------------------
 
-{{ range .ForeignKeys }}
-{{ .Name }}
+{{ .Description }}
+
+- Base type: ` + "`{{ .BaseType }}`" + `{{ if .NotNull }}, not null{{ end }}
+{{ if .Default }}- Default: ` + "`{{ mdescape .Default }}`" + `
+{{ end -}}
+{{ range .Checks }}- ` + "`{{ mdescape . }}`" + `
 {{ end }}
+{{- end }}
+{{- define "composite" }}
+{{ snakeToTitle .Name }}
+-------------------------
+
+{{ .Description }}
+
+| Name | Type | Description |
+|------|------|-------------|
+{{ range .Attributes -}}
+| {{ .Name }} | {{ template "type" . }} | {{ mdescape .Description }} |
 {{ end }}
+{{- end }}
+{{- define "function" }}
+{{ .Name }}
+-----------
 
+` + "`{{ .Signature }}`" + `{{ if .Returns }} returns ` + "`{{ .Returns }}`" + `{{ end }}
 
-Enums
-=====
+{{ .Description }}
+
+- Kind: {{ .Kind }}
+- Language: {{ .Language }}
+- Volatility: {{ .Volatility }}
+{{ end }}
+{{- define "checks" }}{{ range .Checks }} ` + "`{{ mdescape . }}`" + `{{ end }}{{ end }}
+{{- define "default" }}{{ if .Generated }}` + "`GENERATED ALWAYS AS ({{ mdescape .Generated }}) STORED`" + `{{ else if .Identity }}` + "`GENERATED {{ .Identity }} AS IDENTITY`" + `{{ else if .Default }}` + "`{{ mdescape .Default }}`" + `{{ end }}{{ end }}
+{{- define "indexes" }}
+| Index | Columns | Unique | Method | Predicate | Description |
+|-------|---------|--------|--------|-----------|-------------|
+{{ range . -}}
+| {{ .Name }} | {{ mdescape (join .Columns ", ") }} | {{ if .Unique }}yes{{ end }} | {{ .Method }} | {{ if .Predicate }}` + "`{{ mdescape .Predicate }}`" + `{{ end }} | {{ mdescape .Description }} |
+{{ end }}
+{{- end }}
+{{- define "timestamp" }}{{ if . }}{{ .Format "2006-01-02 15:04" }}{{ else }}never{{ end }}{{ end }}
+{{- define "stats" }}
+{{- with .Stats -}}
+| {{ $.Name }} | {{ .NullPercent }} | {{ .DistinctEstimate }} | {{ mdescape (join .CommonValues ", ") }} |
+{{ end }}
+{{- end }}
 
-{{ range .Data.Enums }}
+{{- define "enum" }}
 {{ snakeToTitle .Name }}
 -------------------------
-{{ if .Description }}
+{{ if .Transitions }}
+{{ .Description }}
+
+| From | To |
+|------|----|
+{{ range .Transitions -}}
+| {{ .From }} | {{ .To }} |
+{{ end }}
+` + "```plantuml" + `
+{{ .StateDiagram }}` + "```" + `
+{{ else if .Description }}
 {{ .Description }}
 {{ else }}
 {{ range .Values -}}
 - {{ . }}
 {{ end }}
 {{- end }}
+{{- if .UsedBy }}
+Used by {{ join .UsedBy ", " }}
+{{ end }}
 {{ end }}`
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Maintenance is when a table was last vacuumed and analyzed, by hand or
+// by autovacuum, nil for never since the statistics were reset
+type Maintenance struct {
+	LastVacuum      *time.Time `json:"lastVacuum,omitempty"`
+	LastAutovacuum  *time.Time `json:"lastAutovacuum,omitempty"`
+	LastAnalyze     *time.Time `json:"lastAnalyze,omitempty"`
+	LastAutoanalyze *time.Time `json:"lastAutoanalyze,omitempty"`
+}
+
+// addMaintenance fills in when each table was last vacuumed and analyzed
+// from pg_stat_user_tables, which partitioned tables aren't in
+func addMaintenance(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT relname, last_vacuum, last_autovacuum, last_analyze, last_autoanalyze
+	FROM pg_catalog.pg_stat_user_tables
+	WHERE schemaname = $1`, schema)
+	if err := report.optional("maintenance", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var name string
+		maintenance := Maintenance{}
+		if err := rows.Scan(&name, &maintenance.LastVacuum, &maintenance.LastAutovacuum, &maintenance.LastAnalyze, &maintenance.LastAutoanalyze); err != nil {
+			return err
+		}
+		if table, ok := byName[name]; ok {
+			table.Maintenance = &maintenance
+		}
+	}
+	return rows.Err()
+}
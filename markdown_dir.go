@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// markdownDirDump writes the schema to dir as plain markdown files meant to
+// be committed and reviewed: a README.md index, a page per table in tables/
+// and per view in views/, and types.md and functions.md. Pages are named
+// after the anchor of their table or view, so they stay put between runs,
+// and pages of tables and views which no longer exist are removed.
+func markdownDirDump(schema *Schema, dir string, options MarkdownOptions) error {
+	tpl, err := markdownTemplate(options)
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(template.FuncMap{
+		"typeHref": func(dataType string) string {
+			return "../types.md#" + anchor(dataType)
+		},
+		// Views' pages are also a directory down
+		"tableHref": func(name string) string {
+			return "../tables/" + markdownDirPage(name)
+		},
+	})
+
+	for _, sub := range []string{"tables", "views"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	tables := map[string]bool{}
+	for _, table := range schema.Tables {
+		tables[table.Name] = true
+	}
+	// tableLink links to the page of a documented table, others like those
+	// left out by -exclude are just named
+	tableLink := func(name string) string {
+		if !tables[name] {
+			return name
+		}
+		return fmt.Sprintf("[%s](%s)", name, markdownDirPage(name))
+	}
+
+	written := map[string]bool{}
+	for _, table := range schema.Tables {
+		page := "tables/" + markdownDirPage(table.Name)
+		written[page] = true
+		if err := withWriter(filepath.Join(dir, filepath.FromSlash(page)), options.decorated(func(w io.Writer) error {
+			fmt.Fprintf(w, "[Index](../README.md)\n")
+			if err := tpl.ExecuteTemplate(w, "table", table); err != nil {
+				return err
+			}
+			markdownDirReferences(w, schema, table, tableLink)
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
+	type viewPage struct {
+		name     string
+		template string
+		data     interface{}
+	}
+	views := []viewPage{}
+	for _, view := range schema.Views {
+		views = append(views, viewPage{view.Name, "view", view})
+	}
+	for _, view := range schema.MaterializedViews {
+		views = append(views, viewPage{view.Name, "matview", view})
+	}
+	for _, view := range views {
+		page := "views/" + markdownDirPage(view.name)
+		written[page] = true
+		if err := withWriter(filepath.Join(dir, filepath.FromSlash(page)), options.decorated(func(w io.Writer) error {
+			fmt.Fprintf(w, "[Index](../README.md)\n")
+			return tpl.ExecuteTemplate(w, view.template, view.data)
+		})); err != nil {
+			return err
+		}
+	}
+
+	hasTypes := len(schema.Enums) > 0 || len(schema.Domains) > 0 || len(schema.Types) > 0
+	written["README.md"] = true
+	if err := withWriter(filepath.Join(dir, "README.md"), options.decorated(func(w io.Writer) error {
+		fmt.Fprintf(w, "Database Schema\n===============\n\n| Table | Referenced by | References | Description |\n|-------|---------------|------------|-------------|\n")
+		for _, table := range schema.Tables {
+			fmt.Fprintf(w, "| [%s](tables/%s) | %d | %d | %s |\n", table.Name, markdownDirPage(table.Name), table.FanIn, table.FanOut, markdownDirSummary(table.Description))
+		}
+		if len(views) > 0 {
+			fmt.Fprintf(w, "\n| View | Description |\n|------|-------------|\n")
+			for _, view := range schema.Views {
+				fmt.Fprintf(w, "| [%s](views/%s) | %s |\n", view.Name, markdownDirPage(view.Name), markdownDirSummary(view.Description))
+			}
+			for _, view := range schema.MaterializedViews {
+				fmt.Fprintf(w, "| [%s](views/%s) (materialized) | %s |\n", view.Name, markdownDirPage(view.Name), markdownDirSummary(view.Description))
+			}
+		}
+		if hasTypes {
+			fmt.Fprintf(w, "\nEnums, domains and composite types are in [types.md](types.md).\n")
+		}
+		if len(schema.Functions) > 0 {
+			fmt.Fprintf(w, "\nFunctions are in [functions.md](functions.md).\n")
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	// The types and functions pages go the same way as the tables' pages
+	// when there is nothing left on them, by not being written
+	for _, page := range []struct {
+		filename string
+		needed   bool
+		write    func(w io.Writer) error
+	}{
+		{"types.md", hasTypes, func(w io.Writer) error {
+			fmt.Fprintf(w, "[Index](README.md)\n\nEnums\n=====\n")
+			for _, enum := range schema.Enums {
+				if err := tpl.ExecuteTemplate(w, "enum", enum); err != nil {
+					return err
+				}
+			}
+			if len(schema.Domains) > 0 {
+				fmt.Fprintf(w, "\nDomains\n=======\n")
+			}
+			for _, domain := range schema.Domains {
+				if err := tpl.ExecuteTemplate(w, "domain", domain); err != nil {
+					return err
+				}
+			}
+			if len(schema.Types) > 0 {
+				fmt.Fprintf(w, "\nTypes\n=====\n")
+			}
+			for _, compositeType := range schema.Types {
+				if err := tpl.ExecuteTemplate(w, "composite", compositeType); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"functions.md", len(schema.Functions) > 0, func(w io.Writer) error {
+			fmt.Fprintf(w, "[Index](README.md)\n\nFunctions\n=========\n")
+			for _, function := range schema.Functions {
+				if err := tpl.ExecuteTemplate(w, "function", function); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+	} {
+		if !page.needed {
+			continue
+		}
+		written[page.filename] = true
+		if err := withWriter(filepath.Join(dir, page.filename), options.decorated(page.write)); err != nil {
+			return err
+		}
+	}
+
+	return removeStalePages(dir, written)
+}
+
+// markdownDirPage is the filename of a table's or view's page
+func markdownDirPage(name string) string {
+	return anchor(name) + ".md"
+}
+
+// markdownDirReferences links a table's page to the tables it references
+// and those referencing it
+func markdownDirReferences(w io.Writer, schema *Schema, table Table, tableLink func(string) string) {
+	if len(table.ForeignKeys) > 0 {
+		fmt.Fprintf(w, "\n### References\n\n")
+		for _, fk := range table.ForeignKeys {
+			fmt.Fprintf(w, "- %s (%s)\n", tableLink(fk.RefTable), fk.Name)
+		}
+	}
+
+	referencedBy := []string{}
+	for _, other := range schema.Tables {
+		for _, fk := range other.ForeignKeys {
+			if fk.RefTable == table.Name {
+				referencedBy = append(referencedBy, fmt.Sprintf("- %s (%s)\n", tableLink(other.Name), fk.Name))
+			}
+		}
+	}
+	if len(referencedBy) > 0 {
+		fmt.Fprintf(w, "\n### Referenced By\n\n%s", strings.Join(referencedBy, ""))
+	}
+}
+
+// markdownDirSummary is the first line of a description, fit for a table
+// cell
+func markdownDirSummary(description string) string {
+	return strings.ReplaceAll(strings.SplitN(description, "\n", 2)[0], "|", "\\|")
+}
+
+// pageManifest lists the pages pgdoc wrote to a directory, so that only
+// those are ever removed, and not pages written by hand alongside them
+const pageManifest = ".pgdoc-manifest"
+
+// removeStalePages deletes the pages the manifest in dir lists which weren't
+// written this time, being the pages of objects since dropped, then lists
+// those which were in their place. Written holds slash separated paths
+// relative to dir.
+func removeStalePages(dir string, written map[string]bool) error {
+	manifest := filepath.Join(dir, pageManifest)
+	previous, err := ioutil.ReadFile(manifest)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, page := range strings.Split(string(previous), "\n") {
+		page = strings.TrimSpace(page)
+		if page == "" || written[page] {
+			continue
+		}
+		// Whatever the manifest says, nothing outside dir is touched
+		cleaned := path.Clean(page)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(cleaned))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	pages := make([]string, 0, len(written))
+	for page := range written {
+		pages = append(pages, page)
+	}
+	sort.Strings(pages)
+	return ioutil.WriteFile(manifest, []byte(strings.Join(pages, "\n")+"\n"), 0644)
+}
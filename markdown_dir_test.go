@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRemoveStalePages drops a table between two runs, with a page written
+// by hand alongside the generated ones
+func TestRemoveStalePages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgdoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "tables"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range []string{"tables/users.md", "tables/orgs.md", "tables/notes.md", "outside.md"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(page)), []byte("page"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := removeStalePages(dir, map[string]bool{"tables/users.md": true, "tables/orgs.md": true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeStalePages(dir, map[string]bool{"tables/users.md": true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for page, want := range map[string]bool{
+		"tables/users.md": true,
+		"tables/orgs.md":  false,
+		"tables/notes.md": true,
+		"outside.md":      true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(page)))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s: expected it to exist %v, got %v", page, want, exists)
+		}
+	}
+
+	// Nothing outside the directory, even when the manifest names it
+	if err := ioutil.WriteFile(filepath.Join(dir, "tables", pageManifest), []byte("../outside.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeStalePages(filepath.Join(dir, "tables"), map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.md")); err != nil {
+		t.Errorf("expected outside.md to be left alone: %s", err)
+	}
+}
+
+// TestMarkdownDirHeader checks every page gets the header and footer
+func TestMarkdownDirHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgdoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	schema := &Schema{
+		Tables: []Table{{Name: "users", Columns: []ColumnDefinition{{Name: "name", DataType: "text"}}}},
+		Views:  []View{{Name: "active_users", Columns: []ColumnDefinition{{Name: "name", DataType: "text"}}}},
+	}
+	options := MarkdownOptions{Header: "CONFIDENTIAL\n", Footer: "Generated, do not edit\n"}
+	if err := markdownDirDump(schema, dir, options); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, page := range []string{"README.md", "tables/" + markdownDirPage("users"), "views/" + markdownDirPage("active_users")} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), options.Header) || !strings.HasSuffix(string(data), options.Footer) {
+			t.Errorf("%s: expected the header and footer, got:\n%s", page, data)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// MaterializedView is a view whose rows are stored, and only change when
+// it is refreshed
+type MaterializedView struct {
+	View
+	Indexes []Index `json:"indexes"`
+
+	// Populated is false when the view was created or last refreshed WITH
+	// NO DATA, and so can't be queried
+	Populated bool `json:"populated"`
+
+	// LastAnalyzed stands in for the last refresh, which postgres doesn't
+	// record. Refreshing rewrites every row, which autovacuum follows with
+	// an analyze.
+	LastAnalyzed *time.Time `json:"lastAnalyzed,omitempty"`
+}
+
+// getMaterializedViews reads the materialized views in the schema with their
+// columns and indexes. Descriptions are filled in with the tables'.
+func getMaterializedViews(ctx context.Context, db *sqrlx.Wrapper, schema string, filter NameFilter) ([]MaterializedView, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT mv.matviewname, mv.definition, mv.ispopulated,
+	GREATEST(s.last_analyze, s.last_autoanalyze)
+	FROM pg_catalog.pg_matviews mv
+	LEFT JOIN pg_catalog.pg_stat_user_tables s ON s.schemaname = mv.schemaname AND s.relname = mv.matviewname
+	WHERE mv.schemaname = $1
+	ORDER BY mv.matviewname`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []MaterializedView{}
+	for rows.Next() {
+		view := MaterializedView{}
+		if err := rows.Scan(&view.Name, &view.Definition, &view.Populated, &view.LastAnalyzed); err != nil {
+			return nil, err
+		}
+		if !filter.Includes(view.Name) {
+			continue
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for idx, view := range views {
+		cols, err := getRelationColumns(ctx, db, schema, view.Name)
+		if err != nil {
+			return nil, err
+		}
+		views[idx].Columns = cols
+
+		indexes, err := getIndexes(ctx, db, schema, view.Name)
+		if err != nil {
+			return nil, err
+		}
+		views[idx].Indexes = indexes
+	}
+	return views, nil
+}
+
+// getRelationColumns is getColumns for relations information_schema leaves
+// out, like materialized views. It reads pg_attribute directly, describing
+// the types the same way.
+func getRelationColumns(ctx context.Context, db *sqrlx.Wrapper, schema string, relName string) ([]ColumnDefinition, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT a.attname AS column_name,
+	a.attnum AS ordinal_position,
+	NOT a.attnotnull AS is_nullable,
+	COALESCE(pg_catalog.pg_get_expr(ad.adbin, ad.adrelid), '') AS column_default,
+	'' AS identity,
+	'' AS generated,
+	-- information_schema leaves out the default collation the same way
+	COALESCE(CASE WHEN co.collname <> 'default' THEN co.collname::text END, '') AS collation,
+	CASE WHEN dt.typtype = 'd' THEN dt.typname::text ELSE '' END AS domain,
+	tn.nspname <> 'pg_catalog' AS custom_type,
+	et.oid IS NOT NULL AS is_array,
+	CASE
+		WHEN et.oid IS NOT NULL THEN CASE
+			WHEN et.typnamespace <> 'pg_catalog'::regnamespace THEN et.typname::text
+			ELSE pg_catalog.format_type(et.oid, NULL)
+			END || '[]'
+		WHEN tn.nspname <> 'pg_catalog' THEN t.typname::text
+		WHEN t.oid = 'numeric'::regtype THEN CONCAT('Number(',
+			information_schema._pg_numeric_precision(t.oid, a.atttypmod), ',',
+			information_schema._pg_numeric_scale(t.oid, a.atttypmod), ')')
+		WHEN t.oid = 'bpchar'::regtype THEN CONCAT('Char(',
+			information_schema._pg_char_max_length(t.oid, a.atttypmod), ')')
+		WHEN t.oid = 'timestamptz'::regtype THEN 'timestamp'
+		ELSE pg_catalog.format_type(t.oid, NULL)
+	END AS data_type
+	FROM pg_catalog.pg_attribute a
+	JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_type dt ON dt.oid = a.atttypid
+	-- Domains are described by their base type, as information_schema does
+	JOIN pg_catalog.pg_type t ON t.oid = CASE WHEN dt.typtype = 'd' THEN dt.typbasetype ELSE dt.oid END
+	JOIN pg_catalog.pg_namespace tn ON tn.oid = t.typnamespace
+	LEFT JOIN pg_catalog.pg_type et ON et.oid = t.typelem AND t.typlen = -1
+	LEFT JOIN pg_catalog.pg_collation co ON co.oid = a.attcollation
+	LEFT JOIN pg_catalog.pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+	WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+	ORDER BY a.attnum`, schema, relName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols := make([]ColumnDefinition, 0)
+	for rows.Next() {
+		col := ColumnDefinition{}
+		if err := sqrlx.ScanStruct(rows, &col); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
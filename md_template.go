@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// documentTemplate is what a file in a -md-template directory is named to
+// replace the whole document rather than one of the templates it defines
+const documentTemplate = "markdown"
+
+// parseCustomTemplates parses -md-template over the built in templates, so
+// that anything it leaves out is still there, along with the funcs.
+//
+// A file replaces the whole document, unless it is nothing but {{ define }}
+// blocks, and what it defines replaces the built in templates of the same
+// name, like "table". In a directory each file replaces the template named
+// after it without its extension: table.md replaces "table" and markdown.md
+// the whole document.
+func parseCustomTemplates(tpl *template.Template, filename string) (*template.Template, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return tpl, parseTemplateFile(tpl, documentTemplate, filename)
+	}
+
+	files, err := ioutil.ReadDir(filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		if err := parseTemplateFile(tpl, name, filepath.Join(filename, file.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return tpl, nil
+}
+
+// parseTemplateFile parses a file as the template called name, replacing it
+func parseTemplateFile(tpl *template.Template, name string, filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	// The document is tpl itself, which is what gets executed, while the
+	// others are looked up by name
+	target := tpl
+	if name != documentTemplate {
+		target = tpl.New(name)
+	}
+	if _, err := target.Parse(string(data)); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type MermaidOptions struct {
+	IncludeColumns   bool
+	IncludeDataTypes bool
+}
+
+// mermaidTypePlaceholder stands in for the type when types aren't shown, as
+// Mermaid requires every attribute to have one
+const mermaidTypePlaceholder = "column"
+
+// mermaidUnsafe matches characters Mermaid doesn't allow in attribute types
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]()]`)
+
+// mermaidDump renders the schema as a Mermaid erDiagram
+func mermaidDump(schema *Schema, w io.Writer, options MermaidOptions) error {
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "erDiagram")
+
+	if options.IncludeColumns {
+		for _, table := range schema.Tables {
+			fkColumns := map[string]bool{}
+			for _, fk := range table.ForeignKeys {
+				for _, col := range fk.Columns {
+					fkColumns[col] = true
+				}
+			}
+
+			fmt.Fprintf(out, "  %s {\n", table.Name)
+			for _, col := range table.allColumns() {
+				dataType := mermaidTypePlaceholder
+				if options.IncludeDataTypes {
+					dataType = mermaidUnsafe.ReplaceAllString(col.DataType, "_")
+				}
+				keys := []string{}
+				if col.IsKey {
+					keys = append(keys, "PK")
+				}
+				if fkColumns[col.Name] {
+					keys = append(keys, "FK")
+				}
+				fmt.Fprintf(out, "    %s %s", dataType, col.Name)
+				if len(keys) > 0 {
+					fmt.Fprintf(out, " %s", strings.Join(keys, ","))
+				}
+				// Mermaid only has key markers, so generated goes in the comment
+				comment := col.Description
+				if col.Generated != "" {
+					comment = strings.TrimSpace("(generated) " + comment)
+				}
+				if comment != "" {
+					fmt.Fprintf(out, " \"%s\"", mermaidComment(comment))
+				}
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintln(out, "  }")
+		}
+	}
+
+	for _, table := range schema.Tables {
+		nullable := mermaidNullable(table.allColumns())
+		for _, fk := range table.ForeignKeys {
+			mermaidRelationship(out, table.Name, fk, nullable)
+		}
+		for _, assoc := range table.Polymorphic {
+			for _, target := range assoc.Targets {
+				fmt.Fprintf(out, "  %s }o..o| %s : %s\n", table.Name, target, assoc.Name)
+			}
+		}
+	}
+
+	// Views only have logical keys
+	for _, view := range schema.Views {
+		for _, fk := range view.ForeignKeys {
+			mermaidRelationship(out, view.Name, fk, mermaidNullable(view.Columns))
+		}
+	}
+	for _, view := range schema.MaterializedViews {
+		for _, fk := range view.ForeignKeys {
+			mermaidRelationship(out, view.Name, fk, mermaidNullable(view.Columns))
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func mermaidNullable(columns []ColumnDefinition) map[string]bool {
+	nullable := map[string]bool{}
+	for _, col := range columns {
+		nullable[col.Name] = col.IsNullable
+	}
+	return nullable
+}
+
+// mermaidRelationship writes a foreign key from the named table or view,
+// dotted when it is logical
+func mermaidRelationship(out io.Writer, name string, fk ForeignKeyDefinition, nullable map[string]bool) {
+	parent := "||"
+	for _, col := range fk.Columns {
+		if nullable[col] {
+			parent = "o|"
+		}
+	}
+	line := "--"
+	if fk.Logical {
+		line = ".."
+	}
+	label := fk.Column
+	if fk.IsComposite() {
+		label = strings.Join(fk.Columns, ", ")
+	}
+	if actions := fk.Actions(); actions != "" {
+		label += " " + actions
+	}
+	// Mermaid can't style relationships, so those into other
+	// schemas say so
+	if fk.IsCrossSchema() {
+		label += " (schema " + fk.RefSchema + ")"
+	}
+	if label != fk.Column {
+		label = fmt.Sprintf("%q", label)
+	}
+	fmt.Fprintf(out, "  %s }o%s%s %s : %s\n", name, line, parent, fk.RefTable, label)
+}
+
+// mermaidComment flattens a description into a quoted attribute comment
+func mermaidComment(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	return strings.ReplaceAll(description, `"`, "'")
+}
+
+// mermaidFlowDump renders tables as bare flowchart nodes joined by their
+// foreign keys, for a birds-eye view of which tables depend on which.
+func mermaidFlowDump(schema *Schema, w io.Writer) error {
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "flowchart LR")
+
+	// Foreign tables are dashed
+	foreign := false
+	for _, table := range schema.Tables {
+		if table.Foreign != nil {
+			fmt.Fprintf(out, "  %s:::foreign\n", table.Name)
+			foreign = true
+			continue
+		}
+		fmt.Fprintf(out, "  %s\n", table.Name)
+	}
+	if foreign {
+		fmt.Fprintln(out, "  classDef foreign stroke-dasharray: 5 5")
+	}
+
+	for _, table := range schema.Tables {
+		// Several keys between the same pair of tables become one edge
+		refTables := []string{}
+		columns := map[string][]string{}
+		enforced := map[string]bool{}
+		crossSchema := map[string]bool{}
+		for _, fk := range table.ForeignKeys {
+			crossSchema[fk.RefTable] = fk.IsCrossSchema()
+			if _, ok := columns[fk.RefTable]; !ok {
+				refTables = append(refTables, fk.RefTable)
+			}
+			columns[fk.RefTable] = append(columns[fk.RefTable], fk.ColumnList())
+			enforced[fk.RefTable] = enforced[fk.RefTable] || !fk.Logical
+		}
+		for _, refTable := range refTables {
+			arrow := "-->"
+			if !enforced[refTable] {
+				arrow = "-.->"
+			}
+			// Thick, when into another schema
+			if crossSchema[refTable] && enforced[refTable] {
+				arrow = "==>"
+			}
+			fmt.Fprintf(out, "  %s %s|%s| %s\n", table.Name, arrow, strings.Join(columns[refTable], ", "), refTable)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
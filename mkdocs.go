@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// mkdocsDump writes a MkDocs project to dir: docs/ with an index, a
+// markdown page per table, pages for the enums and functions, and an
+// mkdocs.yml using the Material theme whose nav lists them all
+func mkdocsDump(schema *Schema, dir string, options MarkdownOptions) error {
+	tpl, err := markdownTemplate(options)
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(template.FuncMap{
+		"typeHref": func(dataType string) string {
+			return "../enums.md#" + anchor(dataType)
+		},
+		"tableHref": func(name string) string {
+			return anchor(name) + ".md"
+		},
+	})
+
+	docs := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(filepath.Join(docs, "tables"), 0755); err != nil {
+		return err
+	}
+
+	tableNav := []interface{}{}
+	for _, table := range schema.Tables {
+		page := "tables/" + anchor(table.Name) + ".md"
+		tableNav = append(tableNav, yaml.MapSlice{{Key: table.Name, Value: page}})
+		if err := withWriter(filepath.Join(docs, filepath.FromSlash(page)), options.decorated(func(w io.Writer) error {
+			return tpl.ExecuteTemplate(w, "table", table)
+		})); err != nil {
+			return err
+		}
+	}
+
+	if err := withWriter(filepath.Join(docs, "index.md"), options.decorated(func(w io.Writer) error {
+		fmt.Fprintf(w, "Database Schema\n===============\n\n| Table | Description |\n|-------|-------------|\n")
+		for _, table := range schema.Tables {
+			summary := strings.ReplaceAll(strings.SplitN(table.Description, "\n", 2)[0], "|", "\\|")
+			fmt.Fprintf(w, "| [%s](tables/%s.md) | %s |\n", table.Name, anchor(table.Name), summary)
+		}
+		return nil
+	})); err != nil {
+		return err
+	}
+
+	nav := []interface{}{
+		yaml.MapSlice{{Key: "Overview", Value: "index.md"}},
+		yaml.MapSlice{{Key: "Tables", Value: tableNav}},
+	}
+
+	// Domains and composite types share the enums page, as that's where
+	// typeHref points
+	if len(schema.Enums) > 0 || len(schema.Domains) > 0 || len(schema.Types) > 0 {
+		nav = append(nav, yaml.MapSlice{{Key: "Enums", Value: "enums.md"}})
+		if err := withWriter(filepath.Join(docs, "enums.md"), options.decorated(func(w io.Writer) error {
+			fmt.Fprintf(w, "Enums\n=====\n")
+			for _, enum := range schema.Enums {
+				if err := tpl.ExecuteTemplate(w, "enum", enum); err != nil {
+					return err
+				}
+			}
+			if len(schema.Domains) > 0 {
+				fmt.Fprintf(w, "\nDomains\n=======\n")
+			}
+			for _, domain := range schema.Domains {
+				if err := tpl.ExecuteTemplate(w, "domain", domain); err != nil {
+					return err
+				}
+			}
+			if len(schema.Types) > 0 {
+				fmt.Fprintf(w, "\nTypes\n=====\n")
+			}
+			for _, compositeType := range schema.Types {
+				if err := tpl.ExecuteTemplate(w, "composite", compositeType); err != nil {
+					return err
+				}
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
+	if len(schema.Functions) > 0 {
+		nav = append(nav, yaml.MapSlice{{Key: "Functions", Value: "functions.md"}})
+		if err := withWriter(filepath.Join(docs, "functions.md"), options.decorated(func(w io.Writer) error {
+			fmt.Fprintf(w, "Functions\n=========\n")
+			for _, function := range schema.Functions {
+				if err := tpl.ExecuteTemplate(w, "function", function); err != nil {
+					return err
+				}
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+
+	config := yaml.MapSlice{
+		{Key: "site_name", Value: "Database Schema"},
+		{Key: "theme", Value: yaml.MapSlice{{Key: "name", Value: "material"}}},
+		{Key: "nav", Value: nav},
+	}
+	return withWriter(filepath.Join(dir, "mkdocs.yml"), func(w io.Writer) error {
+		out, err := yaml.Marshal(config)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	})
+}
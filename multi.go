@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type databaseResult struct {
+	name   string
+	schema *Schema
+	report *Report
+	err    error
+}
+
+// documentDatabases introspects several databases at once, writing each
+// one's outputs into its own directory under outputDir and optionally a
+// drift report comparing them all to the first. A database which fails
+// doesn't stop the others, failures are summarised at the end.
+func documentDatabases(urls []string, config Config, options SchemaOptions, outputs Outputs, outputDir string, driftFile string) error {
+	names := databaseNames(urls)
+
+	results := make([]databaseResult, len(urls))
+	wg := sync.WaitGroup{}
+	for idx, pgURL := range urls {
+		wg.Add(1)
+		go func(idx int, pgURL string) {
+			defer wg.Done()
+			dbConfig := config
+			dbConfig.PostgresURL = pgURL
+			result := databaseResult{name: names[idx]}
+			result.schema, result.report, result.err = getSchema(dbConfig)
+			if result.err == nil {
+				result.err = prepareSchema(result.schema, options)
+			}
+			results[idx] = result
+		}(idx, pgURL)
+	}
+	wg.Wait()
+
+	for idx, result := range results {
+		if result.err != nil {
+			continue
+		}
+		logSkipped(result.name+": ", result.report)
+		dir := filepath.Join(outputDir, result.name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			results[idx].err = err
+			continue
+		}
+		if err := writeOutputs(result.schema, outputs, dir); err != nil {
+			results[idx].err = err
+		}
+	}
+
+	if driftFile != "" {
+		if err := withWriter(driftFile, func(w io.Writer) error {
+			return driftDump(results, w)
+		}); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			log.Printf("%s: %s", result.name, result.err.Error())
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d databases failed", failed, len(results))
+	}
+	return nil
+}
+
+// databaseNames names each database by the database in its URL, falling
+// back to its position when the URL doesn't say or the name is taken.
+func databaseNames(urls []string) []string {
+	names := make([]string, len(urls))
+	used := map[string]bool{}
+	for idx, pgURL := range urls {
+		name := ""
+		if parsed, err := url.Parse(pgURL); err == nil {
+			name = strings.Trim(parsed.Path, "/")
+		}
+		if name == "" || used[name] {
+			name = fmt.Sprintf("database%d", idx+1)
+		}
+		used[name] = true
+		names[idx] = name
+	}
+	return names
+}
+
+// driftDump writes a markdown report of how each database differs from the
+// first one which could be introspected.
+func driftDump(results []databaseResult, w io.Writer) error {
+	var baseline *databaseResult
+	for idx := range results {
+		if results[idx].err == nil {
+			baseline = &results[idx]
+			break
+		}
+	}
+
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "Schema Drift\n============\n\n")
+	if baseline == nil {
+		fmt.Fprintf(out, "No database could be introspected.\n")
+		_, err := io.WriteString(w, out.String())
+		return err
+	}
+	fmt.Fprintf(out, "Compared to `%s`.\n", baseline.name)
+
+	for idx := range results {
+		result := &results[idx]
+		if result == baseline {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s\n%s\n\n", result.name, strings.Repeat("-", len(result.name)))
+		if result.schema == nil {
+			fmt.Fprintf(out, "Failed: %s\n", result.err.Error())
+			continue
+		}
+		changes := diffSchemas(baseline.schema, result.schema)
+		if len(changes) == 0 {
+			fmt.Fprintf(out, "No drift.\n")
+			continue
+		}
+		for _, change := range changes {
+			fmt.Fprintf(out, "- %s\n", change.String())
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ndjsonLine is one line of the -ndjson output, a single table, enum or
+// function
+type ndjsonLine struct {
+	Kind     string    `json:"kind"`
+	Table    *Table    `json:"table,omitempty"`
+	Enum     *Enum     `json:"enum,omitempty"`
+	Function *Function `json:"function,omitempty"`
+}
+
+// ndjsonDump writes a JSON object per table, enum and function, each on its
+// own line, so large schemas can be piped through jq or loaded a line at a
+// time instead of as one document
+func ndjsonDump(schema *Schema, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for idx := range schema.Tables {
+		if err := encoder.Encode(ndjsonLine{Kind: "table", Table: &schema.Tables[idx]}); err != nil {
+			return err
+		}
+	}
+	for idx := range schema.Enums {
+		if err := encoder.Encode(ndjsonLine{Kind: "enum", Enum: &schema.Enums[idx]}); err != nil {
+			return err
+		}
+	}
+	for idx := range schema.Functions {
+		if err := encoder.Encode(ndjsonLine{Kind: "function", Function: &schema.Functions[idx]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
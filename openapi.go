@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type openapiDocument struct {
+	OpenAPI    string            `json:"openapi"`
+	Info       openapiInfo       `json:"info"`
+	Paths      struct{}          `json:"paths"`
+	Components openapiComponents `json:"components"`
+}
+
+type openapiInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openapiComponents struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
+
+type openapiObject struct {
+	Type        string                        `json:"type"`
+	Description string                        `json:"description,omitempty"`
+	Properties  map[string]jsonSchemaProperty `json:"properties"`
+	Required    []string                      `json:"required,omitempty"`
+}
+
+// openapiDump writes an OpenAPI 3.1 document whose components.schemas has an
+// object schema per table and a string enum schema per enum. OpenAPI 3.1
+// schemas are JSON Schema, so columns are mapped as for -json-schema with
+// enum columns referring to the enum's schema. It is YAML when asYAML is
+// set, otherwise JSON.
+func openapiDump(schema *Schema, w io.Writer, asYAML bool) error {
+	doc := openapiDocument{
+		OpenAPI: "3.1.0",
+		Info: openapiInfo{
+			Title:   "Database Schema",
+			Version: "1.0.0",
+		},
+		Components: openapiComponents{
+			Schemas: map[string]interface{}{},
+		},
+	}
+
+	enums := enumsByName(schema)
+	for _, table := range schema.Tables {
+		object := openapiObject{
+			Type:        "object",
+			Description: table.Description,
+			Properties:  map[string]jsonSchemaProperty{},
+		}
+		for _, col := range table.allColumns() {
+			var prop jsonSchemaProperty
+			if _, ok := enums[col.DataType]; ok {
+				ref := jsonSchemaProperty{Ref: "#/components/schemas/" + camelCase(col.DataType, true)}
+				prop = jsonSchemaProperty{Description: col.Description, ReadOnly: col.Generated != ""}
+				if col.IsNullable {
+					prop.AnyOf = []jsonSchemaProperty{ref, {Type: "null"}}
+				} else {
+					prop.Ref = ref.Ref
+				}
+			} else {
+				prop = jsonSchemaColumn(col, nil)
+			}
+			object.Properties[col.Name] = prop
+			if !col.IsNullable {
+				object.Required = append(object.Required, col.Name)
+			}
+		}
+		doc.Components.Schemas[camelCase(table.Name, true)] = object
+	}
+
+	for _, enum := range schema.Enums {
+		values := []interface{}{}
+		for _, value := range enum.Values {
+			values = append(values, value)
+		}
+		doc.Components.Schemas[camelCase(enum.Name, true)] = jsonSchemaProperty{
+			Type:        "string",
+			Enum:        values,
+			Description: enum.Description,
+		}
+	}
+
+	if asYAML {
+		return writeYAML(w, doc)
+	}
+	bytes, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes)
+	return err
+}
+
+// isYAMLFile is true for filenames which should be written as YAML
+func isYAMLFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".yaml" || ext == ".yml"
+}
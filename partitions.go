@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// addPartitions fills in the partition key of declaratively partitioned
+// tables, and links partitions and their parents both ways
+func addPartitions(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	version, err := serverVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if version < 100000 {
+		// Inheritance based partitioning has no catalog of its own
+		return nil
+	}
+
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname,
+	COALESCE(pg_catalog.pg_get_partkeydef(c.oid), ''),
+	COALESCE(parent.relname, ''),
+	COALESCE(pg_catalog.pg_get_expr(c.relpartbound, c.oid), '')
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_inherits i ON i.inhrelid = c.oid AND c.relispartition
+	LEFT JOIN pg_catalog.pg_class parent ON parent.oid = i.inhparent
+	WHERE n.nspname = $1 AND (c.relkind = 'p' OR c.relispartition)`, schema)
+	if err := report.optional("partitions", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var name, key, parent, bound string
+		if err := rows.Scan(&name, &key, &parent, &bound); err != nil {
+			return err
+		}
+		table, ok := byName[name]
+		if !ok {
+			continue
+		}
+		table.PartitionKey = key
+		table.PartitionOf = parent
+		table.PartitionBound = bound
+		if parentTable, ok := byName[parent]; ok {
+			parentTable.Partitions = append(parentTable.Partitions, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range byName {
+		sort.Strings(table.Partitions)
+	}
+	return nil
+}
+
+// collapsePartitions returns a copy of the schema without the partitions of
+// any partitioned table in it, for diagrams where they would otherwise be
+// hundreds of identical entities. Foreign keys to a partition point at its
+// topmost parent instead, and the keys partitions inherit from their
+// parent are dropped along with them.
+func collapsePartitions(schema *Schema) *Schema {
+	parents := map[string]string{}
+	for _, table := range schema.Tables {
+		if table.PartitionOf != "" {
+			parents[table.Name] = table.PartitionOf
+		}
+	}
+	present := map[string]bool{}
+	for _, table := range schema.Tables {
+		present[table.Name] = true
+	}
+	root := func(name string) string {
+		for parents[name] != "" && present[parents[name]] {
+			name = parents[name]
+		}
+		return name
+	}
+
+	collapsed := *schema
+	collapsed.Tables = []Table{}
+	for _, table := range schema.Tables {
+		if root(table.Name) != table.Name {
+			continue
+		}
+		fks := make([]ForeignKeyDefinition, 0, len(table.ForeignKeys))
+		for _, fk := range table.ForeignKeys {
+			fk.RefTable = root(fk.RefTable)
+			fks = append(fks, fk)
+		}
+		table.ForeignKeys = fks
+		collapsed.Tables = append(collapsed.Tables, table)
+	}
+	return &collapsed
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultPlantUMLServer is the public PlantUML server. Schemas are sent to
+// it, so set -plantuml-server to a private one for anything sensitive.
+const defaultPlantUMLServer = "https://www.plantuml.com/plantuml"
+
+// plantumlEncoding is base64 with PlantUML's own alphabet
+var plantumlEncoding = base64.NewEncoding("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_").WithPadding(base64.NoPadding)
+
+// plantumlEncode compresses and encodes a diagram for a PlantUML server URL
+func plantumlEncode(diagram string) (string, error) {
+	buf := &bytes.Buffer{}
+	deflater, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(deflater, diagram); err != nil {
+		return "", err
+	}
+	if err := deflater.Close(); err != nil {
+		return "", err
+	}
+	// PlantUML's own encoder pads to whole groups of three bytes with zeros,
+	// which inflating ignores
+	for buf.Len()%3 != 0 {
+		buf.WriteByte(0)
+	}
+	return plantumlEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// plantumlRender has a PlantUML server render the diagram in format, png
+// or svg, and writes the image to w
+func plantumlRender(server string, format string, diagram string, w io.Writer) error {
+	encoded, err := plantumlEncode(diagram)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(server, "/") + "/" + format + "/" + encoded
+
+	client := &http.Client{Timeout: time.Minute}
+	res, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("PlantUML server responded %s", res.Status)
+	}
+	_, err = io.Copy(w, res.Body)
+	return err
+}
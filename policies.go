@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Policy is a row level security policy
+type Policy struct {
+	Name string `json:"name"`
+
+	// Permissive policies are OR'd together, restrictive ones AND'd
+	Permissive bool     `json:"permissive"`
+	Command    string   `json:"command"`
+	Roles      []string `json:"roles"`
+
+	// Using filters the rows which are visible, WithCheck the rows which
+	// may be written
+	Using     string `json:"using,omitempty"`
+	WithCheck string `json:"withCheck,omitempty"`
+}
+
+// DeniesAll is true when row level security is on but there are no
+// policies, so nobody but the owner (and only if it isn't forced) can see
+// any rows. This is rarely intended.
+func (t Table) DeniesAll() bool {
+	return t.RLSEnabled && len(t.Policies) == 0
+}
+
+// addPolicies fills in whether each table has row level security and its
+// policies
+func addPolicies(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, c.relrowsecurity, c.relforcerowsecurity
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')`, schema)
+	if err := report.optional("row level security", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var enabled, forced bool
+		if err := rows.Scan(&name, &enabled, &forced); err != nil {
+			return err
+		}
+		if table, ok := byName[name]; ok {
+			table.RLSEnabled = enabled
+			table.RLSForced = forced
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	policyRows, err := db.QueryRaw(ctx, `SELECT tablename, policyname, permissive = 'PERMISSIVE', cmd, roles::text[],
+	COALESCE(qual, ''), COALESCE(with_check, '')
+	FROM pg_catalog.pg_policies
+	WHERE schemaname = $1
+	ORDER BY tablename, policyname`, schema)
+	if err := report.optional("row level security policies", err); err != nil || policyRows == nil {
+		return err
+	}
+	defer policyRows.Close()
+	for policyRows.Next() {
+		var tableName string
+		policy := Policy{}
+		roles := pq.StringArray{}
+		if err := policyRows.Scan(&tableName, &policy.Name, &policy.Permissive, &policy.Command, &roles, &policy.Using, &policy.WithCheck); err != nil {
+			return err
+		}
+		policy.Roles = []string(roles)
+		if table, ok := byName[tableName]; ok {
+			table.Policies = append(table.Policies, policy)
+		}
+	}
+	if err := policyRows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if table.DeniesAll() {
+			report.warn(warnRLSDeniesAll, table.Name, "row level security is enabled with no policies, every row is hidden")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolymorphicAssociation is a Rails style `<name>_type` / `<name>_id` column
+// pair. There is no constraint behind it, the type column names which table
+// the id refers to row by row.
+type PolymorphicAssociation struct {
+	Name       string   `json:"name"`
+	TypeColumn string   `json:"typeColumn"`
+	IDColumn   string   `json:"idColumn"`
+	Targets    []string `json:"targets"`
+}
+
+type PolymorphicOptions struct {
+	TypeSuffix string
+	IDSuffix   string
+
+	// Targets maps an association name to the tables it may reference.
+	// Associations without targets are noted but not drawn.
+	Targets map[string][]string
+}
+
+// parsePolymorphicTargets parses `name=table,table` flag values
+func parsePolymorphicTargets(values []string) (map[string][]string, error) {
+	targets := map[string][]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("polymorphic target %q should look like name=table,table", value)
+		}
+		for _, table := range strings.Split(parts[1], ",") {
+			targets[parts[0]] = append(targets[parts[0]], strings.TrimSpace(table))
+		}
+	}
+	return targets, nil
+}
+
+func addPolymorphicAssociations(schema *Schema, options PolymorphicOptions) {
+	tableNames := map[string]bool{}
+	for _, table := range schema.Tables {
+		tableNames[table.Name] = true
+	}
+
+	for idx, table := range schema.Tables {
+		// A schema loaded from JSON may already have them
+		schema.Tables[idx].Polymorphic = nil
+
+		columns := map[string]bool{}
+		for _, col := range table.allColumns() {
+			columns[col.Name] = true
+		}
+		constrained := map[string]bool{}
+		for _, fk := range table.ForeignKeys {
+			for _, col := range fk.Columns {
+				constrained[col] = true
+			}
+		}
+
+		for _, col := range table.allColumns() {
+			if !strings.HasSuffix(col.Name, options.TypeSuffix) {
+				continue
+			}
+			name := strings.TrimSuffix(col.Name, options.TypeSuffix)
+			idColumn := name + options.IDSuffix
+			if name == "" || !columns[idColumn] || constrained[idColumn] {
+				continue
+			}
+
+			targets := []string{}
+			for _, target := range options.Targets[name] {
+				if !tableNames[target] {
+					warnf("polymorphic target %s for %s.%s is not a documented table", target, table.Name, name)
+					continue
+				}
+				targets = append(targets, target)
+			}
+
+			schema.Tables[idx].Polymorphic = append(schema.Tables[idx].Polymorphic, PolymorphicAssociation{
+				Name:       name,
+				TypeColumn: col.Name,
+				IDColumn:   idColumn,
+				Targets:    targets,
+			})
+		}
+	}
+}
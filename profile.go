@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// ColumnProfile is what a live query found in a column's data
+type ColumnProfile struct {
+	Rows     int64 `json:"rows"`
+	Nulls    int64 `json:"nulls"`
+	Distinct int64 `json:"distinct"`
+
+	// Min and Max are left out for types with no useful order, like json
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+}
+
+// ProfileOptions choose the columns to profile, as table or table.column,
+// and how hard to work the database doing it
+type ProfileOptions struct {
+	Columns     []string
+	Concurrency int
+
+	// Timeout limits each column's query, those taking longer are skipped
+	Timeout time.Duration
+}
+
+// selects is true when the options pick out the column
+func (o ProfileOptions) selects(table string, column string) bool {
+	for _, name := range o.Columns {
+		if name == table || name == table+"."+column {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfiledColumn is a column's profile with where it came from, for the
+// appendix
+type ProfiledColumn struct {
+	Table   string
+	Column  string
+	Profile ColumnProfile
+}
+
+// profiledColumns lists the profiled columns, in table order
+func profiledColumns(schema *Schema) []ProfiledColumn {
+	profiled := []ProfiledColumn{}
+	for _, table := range schema.Tables {
+		for _, col := range table.allColumns() {
+			if col.Profile != nil {
+				profiled = append(profiled, ProfiledColumn{Table: table.Name, Column: col.Name, Profile: *col.Profile})
+			}
+		}
+	}
+	return profiled
+}
+
+// profileBounds is the min and max to select for a column, compared as
+// their own type when that orders them usefully and as text otherwise.
+// Those of redacted columns would give their values away.
+func profileBounds(col ColumnDefinition, quoted string, redacted bool) string {
+	if redacted {
+		return "NULL, NULL"
+	}
+	switch dataTypeKind(col.DataType) {
+	case kindJSON, kindBytes, kindArray:
+		return "NULL, NULL"
+	case kindInteger, kindBigInteger, kindFloat, kindDecimal, kindTimestamp, kindDate, kindTime, kindUUID:
+		return "min(" + quoted + ")::text, max(" + quoted + ")::text"
+	default:
+		return "min(" + quoted + "::text), max(" + quoted + "::text)"
+	}
+}
+
+// addProfiles counts the rows, nulls and distinct values of the selected
+// columns and finds their min and max, a query per column, several at
+// once. These scan the whole table, so are best run against a replica.
+func addProfiles(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, options ProfileOptions, redact []string, report *Report) error {
+	type job struct {
+		table    string
+		col      *ColumnDefinition
+		redacted bool
+		profile  ColumnProfile
+		err      error
+	}
+	jobs := []*job{}
+	for idx := range tables {
+		table := &tables[idx]
+		if table.Foreign != nil {
+			continue
+		}
+		for _, cols := range [][]ColumnDefinition{table.KeyColumns, table.Columns} {
+			for colIdx := range cols {
+				if options.selects(table.Name, cols[colIdx].Name) {
+					jobs = append(jobs, &job{table: table.Name, col: &cols[colIdx], redacted: columnListed(redact, table.Name, cols[colIdx].Name)})
+				}
+			}
+		}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for _, j := range jobs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(j *job) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			j.profile, j.err = profileColumn(ctx, db, schema, j.table, *j.col, j.redacted, options.Timeout)
+		}(j)
+	}
+	wg.Wait()
+
+	// The report isn't safe to share between the queries
+	for _, j := range jobs {
+		what := j.table + "." + j.col.Name
+		if errors.Is(j.err, context.DeadlineExceeded) {
+			report.warn(warnSkipped, what, "profiling took longer than %s", options.Timeout)
+			continue
+		}
+		if err := report.optional("profile of "+what, j.err); err != nil {
+			return err
+		}
+		if j.err == nil {
+			profile := j.profile
+			j.col.Profile = &profile
+		}
+	}
+	return nil
+}
+
+func profileColumn(ctx context.Context, db *sqrlx.Wrapper, schema string, table string, col ColumnDefinition, redacted bool, timeout time.Duration) (ColumnProfile, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	quoted := pq.QuoteIdentifier(col.Name)
+	// Not every type has equality, but all have a text form to compare
+	rows, err := db.QueryRaw(ctx, fmt.Sprintf(`SELECT count(*), count(%s), count(DISTINCT %s::text), %s
+	FROM %s.%s`, quoted, quoted, profileBounds(col, quoted, redacted), pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)))
+	if err != nil {
+		return ColumnProfile{}, timedOut(ctx, err)
+	}
+	defer rows.Close()
+
+	profile := ColumnProfile{}
+	for rows.Next() {
+		var notNull int64
+		var min, max sql.NullString
+		if err := rows.Scan(&profile.Rows, &notNull, &profile.Distinct, &min, &max); err != nil {
+			return ColumnProfile{}, err
+		}
+		profile.Nulls = profile.Rows - notNull
+		profile.Min = min.String
+		profile.Max = max.String
+	}
+	if err := rows.Err(); err != nil {
+		return ColumnProfile{}, timedOut(ctx, err)
+	}
+	return profile, nil
+}
+
+// timedOut is the context's error when it has run out, rather than the
+// error of postgres cancelling the query
+func timedOut(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// protoTypes are the proto3 scalar types for each kind, kinds missing here
+// are strings
+var protoTypes = map[typeKind]string{
+	kindInteger:    "int32",
+	kindBigInteger: "int64",
+	kindFloat:      "double",
+	kindBoolean:    "bool",
+	kindTimestamp:  "google.protobuf.Timestamp",
+	kindJSON:       "google.protobuf.Value",
+	kindBytes:      "bytes",
+}
+
+// protoImports are the well known type files each type needs
+var protoImports = map[string]string{
+	"google.protobuf.Timestamp": "google/protobuf/timestamp.proto",
+	"google.protobuf.Value":     "google/protobuf/struct.proto",
+}
+
+// protoDump renders the schema as a proto3 file in the given package, a
+// message per table and an enum per enum. Field numbers are the columns'
+// positions in the table, so they stay stable while columns are only added.
+func protoDump(schema *Schema, w io.Writer, pkg string) error {
+	enums := enumsByName(schema)
+	imports := map[string]bool{}
+	body := &strings.Builder{}
+
+	for _, table := range schema.Tables {
+		protoComment(body, "", table.Description)
+		fmt.Fprintf(body, "message %s {\n", camelCase(table.Name, true))
+		for idx, col := range table.allColumns() {
+			var fieldType string
+			label := ""
+			if _, ok := enums[col.DataType]; ok {
+				fieldType = camelCase(col.DataType, true)
+			} else {
+				kind := dataTypeKind(col.DataType)
+				fieldType = "string"
+				if protoType, ok := protoTypes[kind]; ok {
+					fieldType = protoType
+				}
+				if kind == kindArray {
+					label = "repeated "
+				}
+			}
+			if file, ok := protoImports[fieldType]; ok {
+				imports[file] = true
+			}
+			if col.IsNullable && label == "" && !strings.HasPrefix(fieldType, "google.protobuf.") {
+				label = "optional "
+			}
+
+			number := col.Ordinal
+			if number == 0 {
+				number = idx + 1
+			}
+			protoComment(body, "  ", col.Description)
+			fmt.Fprintf(body, "  %s%s %s = %d;\n", label, fieldType, protoFieldName(col.Name), number)
+		}
+		fmt.Fprintf(body, "}\n\n")
+	}
+
+	for _, enum := range schema.Enums {
+		prefix := strings.ToUpper(protoFieldName(enum.Name)) + "_"
+		protoComment(body, "", enum.Description)
+		fmt.Fprintf(body, "enum %s {\n", camelCase(enum.Name, true))
+		fmt.Fprintf(body, "  %sUNSPECIFIED = 0;\n", prefix)
+		for idx, value := range enum.Values {
+			fmt.Fprintf(body, "  %s%s = %d;\n", prefix, strings.ToUpper(protoFieldName(value)), idx+1)
+		}
+		fmt.Fprintf(body, "}\n\n")
+	}
+
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "syntax = \"proto3\";\n\npackage %s;\n\n", pkg)
+	files := []string{}
+	for file := range imports {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		fmt.Fprintf(out, "import \"%s\";\n", file)
+	}
+	if len(files) > 0 {
+		fmt.Fprintln(out)
+	}
+	out.WriteString(strings.TrimSuffix(body.String(), "\n"))
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// protoFieldName replaces anything proto identifiers can't hold with an
+// underscore
+func protoFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func protoComment(out *strings.Builder, indent string, description string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintln(out, strings.TrimRight(indent+"// "+line, " "))
+	}
+}
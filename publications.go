@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Publication is a logical replication publication. Publications are per
+// database, so Tables are schema qualified and may be outside the
+// documented schema.
+type Publication struct {
+	Name string `json:"name"`
+
+	// AllTables publishes every table, including those created later
+	AllTables bool `json:"allTables"`
+
+	// Operations are the changes streamed, INSERT, UPDATE, DELETE and
+	// TRUNCATE
+	Operations []string `json:"operations"`
+	Tables     []string `json:"tables,omitempty"`
+}
+
+// getPublications reads the publications and marks the tables of schema
+// they stream with their names
+func getPublications(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) ([]Publication, error) {
+	version, err := serverVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	// Logical replication arrived in Postgres 10, TRUNCATE in 11
+	if version < 100000 {
+		return nil, nil
+	}
+	truncate := "false"
+	if version >= 110000 {
+		truncate = "p.pubtruncate"
+	}
+
+	rows, err := db.QueryRaw(ctx, `SELECT p.pubname, p.puballtables, p.pubinsert, p.pubupdate, p.pubdelete, `+truncate+`
+	FROM pg_catalog.pg_publication p
+	ORDER BY p.pubname`)
+	if err := report.optional("publications", err); err != nil || rows == nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	publications := []Publication{}
+	byName := map[string]int{}
+	for rows.Next() {
+		publication := Publication{}
+		var insert, update, delete, truncate bool
+		if err := rows.Scan(&publication.Name, &publication.AllTables, &insert, &update, &delete, &truncate); err != nil {
+			return nil, err
+		}
+		for _, operation := range []struct {
+			enabled bool
+			name    string
+		}{
+			{insert, "INSERT"},
+			{update, "UPDATE"},
+			{delete, "DELETE"},
+			{truncate, "TRUNCATE"},
+		} {
+			if operation.enabled {
+				publication.Operations = append(publication.Operations, operation.name)
+			}
+		}
+		byName[publication.Name] = len(publications)
+		publications = append(publications, publication)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tableRows, err := db.QueryRaw(ctx, `SELECT pubname, schemaname, tablename
+	FROM pg_catalog.pg_publication_tables
+	ORDER BY pubname, schemaname, tablename`)
+	if err := report.optional("publication tables", err); err != nil || tableRows == nil {
+		return publications, err
+	}
+	defer tableRows.Close()
+
+	tablesByName := map[string]*Table{}
+	for idx := range tables {
+		tablesByName[tables[idx].Name] = &tables[idx]
+	}
+
+	for tableRows.Next() {
+		var pubName, tableSchema, tableName string
+		if err := tableRows.Scan(&pubName, &tableSchema, &tableName); err != nil {
+			return nil, err
+		}
+		idx, ok := byName[pubName]
+		if !ok {
+			continue
+		}
+		// Every table is in an all tables publication, so they aren't
+		// listed
+		if !publications[idx].AllTables {
+			publications[idx].Tables = append(publications[idx].Tables, tableSchema+"."+tableName)
+		}
+		if table, ok := tablesByName[tableName]; ok && tableSchema == schema {
+			table.Publications = append(table.Publications, pubName)
+		}
+	}
+	return publications, tableRows.Err()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// pumlJSONDump renders the schema, or just the named table, as a PlantUML
+// @startjson diagram
+func pumlJSONDump(schema *Schema, w io.Writer, tableName string) error {
+	var data interface{} = schema
+	if tableName != "" {
+		data = nil
+		for _, table := range schema.Tables {
+			if table.Name == tableName {
+				data = table
+				break
+			}
+		}
+		if data == nil {
+			return fmt.Errorf("no table %s to render as PUML JSON", tableName)
+		}
+	}
+
+	bytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "@startjson\n%s\n@endjson\n", bytes)
+	return err
+}
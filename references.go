@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sort"
+)
+
+// countReferences sets each table's FanOut (foreign keys it has) and FanIn
+// (foreign keys pointing at it, self references included)
+func countReferences(schema *Schema) {
+	fanIn := map[string]int{}
+	for _, table := range schema.Tables {
+		for _, fk := range table.ForeignKeys {
+			fanIn[fk.RefTable]++
+		}
+	}
+	for idx, table := range schema.Tables {
+		schema.Tables[idx].FanOut = len(table.ForeignKeys)
+		schema.Tables[idx].FanIn = fanIn[table.Name]
+	}
+}
+
+// addEnumUsage sets each enum's UsedBy to the table columns of its type,
+// arrays of it included
+func addEnumUsage(schema *Schema) {
+	usedBy := map[string][]string{}
+	for _, table := range schema.Tables {
+		for _, col := range table.KeyColumns {
+			usedBy[col.ElementType()] = append(usedBy[col.ElementType()], table.Name+"."+col.Name)
+		}
+		for _, col := range table.Columns {
+			usedBy[col.ElementType()] = append(usedBy[col.ElementType()], table.Name+"."+col.Name)
+		}
+	}
+	for idx, enum := range schema.Enums {
+		schema.Enums[idx].UsedBy = usedBy[enum.Name]
+	}
+}
+
+// topHubs returns the n most referenced tables, most referenced first.
+// Tables nothing references aren't hubs and are left out.
+func topHubs(schema *Schema, n int) []Table {
+	hubs := []Table{}
+	for _, table := range schema.Tables {
+		if table.FanIn > 0 {
+			hubs = append(hubs, table)
+		}
+	}
+	sort.SliceStable(hubs, func(i, j int) bool {
+		return hubs[i].FanIn > hubs[j].FanIn
+	})
+	if len(hubs) > n {
+		hubs = hubs[:n]
+	}
+	return hubs
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// getReplicaLag returns whether the database is a replica, and if so how far
+// behind its primary it is. The lag is nil when the replica hasn't replayed
+// a transaction since it started, so there is no replay timestamp.
+func getReplicaLag(ctx context.Context, db *sqrlx.Wrapper) (bool, *time.Duration, error) {
+	// A replica which has replayed everything it received is up to date
+	// however long ago the last transaction was
+	rows, err := db.QueryRaw(ctx, `SELECT pg_is_in_recovery(),
+	CASE WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END`)
+	if err != nil {
+		return false, nil, err
+	}
+	defer rows.Close()
+
+	isReplica := false
+	seconds := sql.NullFloat64{}
+	for rows.Next() {
+		if err := rows.Scan(&isReplica, &seconds); err != nil {
+			return false, nil, err
+		}
+	}
+	if err := rows.Err(); err != nil || !seconds.Valid {
+		return isReplica, nil, err
+	}
+	lag := time.Duration(seconds.Float64 * float64(time.Second))
+	return isReplica, &lag, nil
+}
+
+// checkReplicaLag errors when a replica's lag is over max. A max of zero
+// disables the check. Without a replay timestamp there's no telling the lag,
+// which is given the benefit of the doubt like a primary.
+func checkReplicaLag(isReplica bool, lag *time.Duration, max time.Duration) error {
+	if !isReplica || lag == nil || max <= 0 || *lag <= max {
+		return nil
+	}
+	return fmt.Errorf("replica is %s behind its primary, more than the allowed %s: the schema may be missing recent migrations", lag.Round(time.Second), max)
+}
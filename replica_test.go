@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckReplicaLag(t *testing.T) {
+	lag := func(d time.Duration) *time.Duration {
+		return &d
+	}
+
+	for _, tc := range []struct {
+		name      string
+		isReplica bool
+		lag       *time.Duration
+		max       time.Duration
+		wantErr   bool
+	}{{
+		name: "not a replica",
+		lag:  lag(0),
+		max:  time.Minute,
+	}, {
+		name:      "under the limit",
+		isReplica: true,
+		lag:       lag(10 * time.Second),
+		max:       time.Minute,
+	}, {
+		name:      "at the limit",
+		isReplica: true,
+		lag:       lag(time.Minute),
+		max:       time.Minute,
+	}, {
+		name:      "over the limit",
+		isReplica: true,
+		lag:       lag(5 * time.Minute),
+		max:       time.Minute,
+		wantErr:   true,
+	}, {
+		name:      "no replay timestamp",
+		isReplica: true,
+		max:       time.Minute,
+	}, {
+		name:      "no limit",
+		isReplica: true,
+		lag:       lag(time.Hour),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkReplicaLag(tc.isReplica, tc.lag, tc.max)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// warnSkipped is something which couldn't be introspected, usually for
+	// lack of privileges
+	warnSkipped = "skipped"
+
+	warnMissingDescription  = "missing-description"
+	warnBrokenTypeLink      = "broken-type-link"
+	warnRLSDeniesAll        = "rls-denies-all"
+	warnDuplicateIndex      = "duplicate-index"
+	warnRedundantIndex      = "redundant-index"
+	warnUnindexedForeignKey = "unindexed-foreign-key"
+)
+
+// Report describes an introspection run, for callers who want to act on
+// what was found rather than just render it
+type Report struct {
+	Tables            int `json:"tables"`
+	Views             int `json:"views"`
+	MaterializedViews int `json:"materializedViews"`
+	Columns           int `json:"columns"`
+	ForeignKeys       int `json:"foreignKeys"`
+	Enums             int `json:"enums"`
+	Functions         int `json:"functions"`
+
+	// Timings is the time spent in each phase of introspection
+	Timings map[string]time.Duration `json:"timings"`
+
+	Warnings []Warning `json:"warnings"`
+}
+
+type Warning struct {
+	Kind    string `json:"kind"`
+	Object  string `json:"object"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s %s: %s", w.Kind, w.Object, w.Message)
+}
+
+func newReport() *Report {
+	return &Report{
+		Timings:  map[string]time.Duration{},
+		Warnings: []Warning{},
+	}
+}
+
+func (r *Report) warn(kind string, object string, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, Warning{
+		Kind:    kind,
+		Object:  object,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// timed adds the time since start to the phase's total
+func (r *Report) timed(phase string, start time.Time) {
+	r.Timings[phase] += time.Since(start)
+}
+
+// optional swallows permission errors from queries which only enrich the
+// output, recording a warning so the core schema can still be documented.
+func (r *Report) optional(what string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if isPermissionDenied(err) {
+		r.warn(warnSkipped, what, "%s", err.Error())
+		return nil
+	}
+	return fmt.Errorf("Looking up %s %w", what, err)
+}
+
+// analyse counts the schema and checks it for gaps in the documentation
+func (r *Report) analyse(schema *Schema) {
+	enums := map[string]bool{}
+	for _, enum := range schema.Enums {
+		enums[enum.Name] = true
+		if enum.Description == "" {
+			r.warn(warnMissingDescription, enum.Name, "enum has no comment")
+		}
+	}
+	// Composite types are linked to like enums
+	for _, compositeType := range schema.Types {
+		enums[compositeType.Name] = true
+	}
+
+	r.Tables = len(schema.Tables)
+	r.Views = len(schema.Views)
+	r.MaterializedViews = len(schema.MaterializedViews)
+	r.Enums = len(schema.Enums)
+	r.Functions = len(schema.Functions)
+	for _, table := range schema.Tables {
+		if table.Description == "" {
+			r.warn(warnMissingDescription, table.Name, "table has no comment")
+		}
+		for _, col := range table.allColumns() {
+			r.Columns++
+			name := table.Name + "." + col.Name
+			if col.Description == "" {
+				r.warn(warnMissingDescription, name, "column has no comment")
+			}
+			if col.CustomType && !enums[col.ElementType()] {
+				r.warn(warnBrokenTypeLink, name, "type %s is not documented, its link won't resolve", col.ElementType())
+			}
+		}
+		r.ForeignKeys += len(table.ForeignKeys)
+		r.analyseIndexes(table.Name, table.Indexes)
+		r.analyseForeignKeyIndexes(table)
+	}
+	for _, view := range schema.MaterializedViews {
+		r.analyseIndexes(view.Name, view.Indexes)
+	}
+}
+
+func reportDump(report *Report, w io.Writer) error {
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "Tables: %d\n", report.Tables)
+	fmt.Fprintf(out, "Views: %d\n", report.Views)
+	fmt.Fprintf(out, "Materialized Views: %d\n", report.MaterializedViews)
+	fmt.Fprintf(out, "Columns: %d\n", report.Columns)
+	fmt.Fprintf(out, "Foreign keys: %d\n", report.ForeignKeys)
+	fmt.Fprintf(out, "Enums: %d\n", report.Enums)
+	fmt.Fprintf(out, "Functions: %d\n", report.Functions)
+
+	if len(report.Timings) > 0 {
+		fmt.Fprintf(out, "\nTimings\n-------\n\n")
+		for _, phase := range []string{"tables", "columns", "constraints", "indexes", "exclusions", "views", "materialized views", "view dependencies", "enums", "domains", "types", "functions", "descriptions", "view column origins", "storage", "partitions", "foreign tables", "policies", "triggers", "rules", "privileges", "stats", "sizes", "maintenance", "sample", "profile", "sequences", "extensions", "publications", "event triggers"} {
+			if duration, ok := report.Timings[phase]; ok {
+				fmt.Fprintf(out, "- %s: %s\n", phase, duration)
+			}
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Fprintf(out, "\nWarnings\n--------\n\n")
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(out, "- %s\n", warning.String())
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// TestReportOptional simulates a restricted role, whose queries of the
+// catalogs it may not read fail with insufficient_privilege
+func TestReportOptional(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		wantErr  bool
+		warnings int
+	}{{
+		name: "no error",
+	}, {
+		name:     "permission denied",
+		err:      &pq.Error{Code: "42501", Message: "permission denied for table pg_statistic"},
+		warnings: 1,
+	}, {
+		name:     "wrapped permission denied",
+		err:      fmt.Errorf("reading grants: %w", &pq.Error{Code: "42501"}),
+		warnings: 1,
+	}, {
+		name:    "other postgres error",
+		err:     &pq.Error{Code: "42P01", Message: "relation does not exist"},
+		wantErr: true,
+	}, {
+		name:    "connection error",
+		err:     errors.New("connection refused"),
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := newReport()
+			err := report.optional("column statistics", tc.err)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected the error to wrap %v, got %v", tc.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(report.Warnings) != tc.warnings {
+				t.Fatalf("expected %d warnings, got %v", tc.warnings, report.Warnings)
+			}
+			for _, warning := range report.Warnings {
+				if warning.Kind != warnSkipped || warning.Object != "column statistics" {
+					t.Errorf("unexpected warning %s", warning)
+				}
+			}
+		})
+	}
+}
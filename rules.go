@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Rule is a rewrite rule on a table
+type Rule struct {
+	Name string `json:"name"`
+
+	// Definition is the CREATE RULE statement
+	Definition string `json:"definition"`
+}
+
+// addRules fills in each table's rewrite rules. pg_rules leaves out the
+// rules implementing views.
+func addRules(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT tablename, rulename, definition
+	FROM pg_catalog.pg_rules
+	WHERE schemaname = $1
+	ORDER BY tablename, rulename`, schema)
+	if err := report.optional("rules", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var tableName string
+		rule := Rule{}
+		if err := rows.Scan(&tableName, &rule.Name, &rule.Definition); err != nil {
+			return err
+		}
+		if table, ok := byName[tableName]; ok {
+			table.Rules = append(table.Rules, rule)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+const (
+	// redactedValue replaces the values of redacted columns
+	redactedValue = "[redacted]"
+
+	// sampleValueWidth is how much of a long value markdown and HTML show
+	sampleValueWidth = 60
+)
+
+// SampleRows are example rows of a table, as text, with nil for NULL
+type SampleRows struct {
+	Columns []string    `json:"columns"`
+	Rows    [][]*string `json:"rows"`
+}
+
+// Formatted is the rows for display, with NULL spelled out and long values
+// cut short
+func (s SampleRows) Formatted() [][]string {
+	formatted := make([][]string, len(s.Rows))
+	for rowIdx, row := range s.Rows {
+		formatted[rowIdx] = make([]string, len(row))
+		for idx, value := range row {
+			switch {
+			case value == nil:
+				formatted[rowIdx][idx] = "NULL"
+			case len([]rune(*value)) > sampleValueWidth:
+				formatted[rowIdx][idx] = string([]rune(*value)[:sampleValueWidth]) + "…"
+			default:
+				formatted[rowIdx][idx] = *value
+			}
+		}
+	}
+	return formatted
+}
+
+// addSamples reads up to limit rows of each table. Redacted columns aren't
+// read at all. Foreign tables are left out rather than query their remote
+// server.
+func addSamples(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, limit int, redact []string, report *Report) error {
+	for idx := range tables {
+		table := &tables[idx]
+		if table.Foreign != nil {
+			continue
+		}
+
+		sample := &SampleRows{}
+		selects := []string{}
+		read := []int{}
+		for colIdx, col := range table.allColumns() {
+			sample.Columns = append(sample.Columns, col.Name)
+			if columnListed(redact, table.Name, col.Name) {
+				continue
+			}
+			selects = append(selects, pq.QuoteIdentifier(col.Name)+"::text")
+			read = append(read, colIdx)
+		}
+		if len(selects) == 0 {
+			continue
+		}
+
+		rows, err := db.QueryRaw(ctx, `SELECT `+strings.Join(selects, ", ")+`
+		FROM `+pq.QuoteIdentifier(schema)+`.`+pq.QuoteIdentifier(table.Name)+`
+		LIMIT $1`, limit)
+		if err := report.optional("sample rows of "+table.Name, err); err != nil {
+			return err
+		}
+		if rows == nil {
+			continue
+		}
+
+		for rows.Next() {
+			values := make([]sql.NullString, len(selects))
+			dest := make([]interface{}, len(values))
+			for valueIdx := range values {
+				dest[valueIdx] = &values[valueIdx]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+
+			row := make([]*string, len(sample.Columns))
+			for colIdx := range row {
+				redacted := redactedValue
+				row[colIdx] = &redacted
+			}
+			for valueIdx, colIdx := range read {
+				row[colIdx] = nil
+				if values[valueIdx].Valid {
+					row[colIdx] = &values[valueIdx].String
+				}
+			}
+			sample.Rows = append(sample.Rows, row)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(sample.Rows) > 0 {
+			table.Sample = sample
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// crossSchemaColor marks foreign keys into another schema in diagrams
+const crossSchemaColor = "#1f77b4"
+
+// getSchemas introspects each of the postgres schemas and merges them into
+// one Schema. With more than one, names are qualified with their schema,
+// as in sales.orders, so the same name can appear in several.
+func getSchemas(ctx context.Context, db *sqrlx.Wrapper, schemas []string, config Config, report *Report) (*Schema, error) {
+	if len(schemas) == 1 {
+		return getFullSchema(ctx, db, schemas[0], config, report)
+	}
+
+	read := make([]*Schema, len(schemas))
+	for idx, schema := range schemas {
+		fullSchema, err := getFullSchema(ctx, db, schema, config, report)
+		if err != nil {
+			return nil, err
+		}
+		read[idx] = fullSchema
+	}
+
+	// Columns only name their custom type, so it is looked for in the
+	// column's own schema, then the others in order
+	typeSchemas := map[string][]string{}
+	for idx, fullSchema := range read {
+		names := []string{}
+		for _, enum := range fullSchema.Enums {
+			names = append(names, enum.Name)
+		}
+		for _, domain := range fullSchema.Domains {
+			names = append(names, domain.Name)
+		}
+		for _, compositeType := range fullSchema.Types {
+			names = append(names, compositeType.Name)
+		}
+		for _, name := range names {
+			typeSchemas[name] = append(typeSchemas[name], schemas[idx])
+		}
+	}
+
+	merged := &Schema{
+		Schemas: schemas,
+		Tables:  []Table{},
+		Enums:   []Enum{},
+		// Extensions, publications and event triggers are per database, so
+		// each schema read them all
+		Extensions:    read[0].Extensions,
+		Publications:  read[0].Publications,
+		EventTriggers: read[0].EventTriggers,
+	}
+	for idx, fullSchema := range read {
+		qualifySchema(fullSchema, schemas[idx], typeSchemas)
+		merged.Tables = append(merged.Tables, fullSchema.Tables...)
+		merged.Views = append(merged.Views, fullSchema.Views...)
+		merged.MaterializedViews = append(merged.MaterializedViews, fullSchema.MaterializedViews...)
+		merged.Enums = append(merged.Enums, fullSchema.Enums...)
+		merged.Domains = append(merged.Domains, fullSchema.Domains...)
+		merged.Types = append(merged.Types, fullSchema.Types...)
+		merged.Functions = append(merged.Functions, fullSchema.Functions...)
+		merged.Sequences = append(merged.Sequences, fullSchema.Sequences...)
+	}
+	return merged, nil
+}
+
+// getSchemaNames lists the schemas in the database other than postgres' own
+func getSchemaNames(ctx context.Context, db *sqrlx.Wrapper) ([]string, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT nspname
+	FROM pg_catalog.pg_namespace
+	WHERE nspname NOT IN ('pg_catalog', 'information_schema', 'pg_toast')
+	AND nspname NOT LIKE 'pg\_temp\_%'
+	AND nspname NOT LIKE 'pg\_toast\_temp\_%'
+	ORDER BY nspname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no schemas found")
+	}
+	return schemas, nil
+}
+
+// schemaOf is the one of schemas a qualified name is in, empty when it is
+// in none of them
+func schemaOf(name string, schemas []string) string {
+	found := ""
+	for _, schema := range schemas {
+		if strings.HasPrefix(name, schema+".") && len(schema) > len(found) {
+			found = schema
+		}
+	}
+	return found
+}
+
+// SchemaTables are the tables of one of several documented schemas
+type SchemaTables struct {
+	Name   string
+	Tables []Table
+}
+
+// tablesBySchema groups the tables by schema when several are documented,
+// and is empty otherwise
+func tablesBySchema(fullSchema *Schema) []SchemaTables {
+	if len(fullSchema.Schemas) < 2 {
+		return nil
+	}
+	grouped := make([]SchemaTables, len(fullSchema.Schemas))
+	for idx, schema := range fullSchema.Schemas {
+		grouped[idx].Name = schema
+		for _, table := range fullSchema.Tables {
+			if schemaOf(table.Name, fullSchema.Schemas) == schema {
+				grouped[idx].Tables = append(grouped[idx].Tables, table)
+			}
+		}
+	}
+	return grouped
+}
+
+// qualifySchema prefixes the names in a Schema read from one postgres
+// schema with it. References into other schemas are already qualified.
+func qualifySchema(fullSchema *Schema, schema string, typeSchemas map[string][]string) {
+	qualify := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		return schema + "." + name
+	}
+
+	qualifyType := func(name string) string {
+		found := typeSchemas[name]
+		if len(found) == 0 {
+			return name
+		}
+		for _, typeSchema := range found {
+			if typeSchema == schema {
+				return qualify(name)
+			}
+		}
+		return found[0] + "." + name
+	}
+
+	qualifyColumns := func(cols []ColumnDefinition) {
+		for idx, col := range cols {
+			if col.CustomType {
+				elementType := col.ElementType()
+				cols[idx].DataType = qualifyType(elementType) + strings.TrimPrefix(col.DataType, elementType)
+			}
+			if col.Domain != "" {
+				cols[idx].Domain = qualifyType(col.Domain)
+			}
+			cols[idx].Sequence = qualify(col.Sequence)
+		}
+	}
+
+	// Views name what they select from in their own schema unqualified
+	local := map[string]bool{}
+	for _, table := range fullSchema.Tables {
+		local[table.Name] = true
+	}
+	for _, view := range viewDependencies(fullSchema) {
+		local[view.name] = true
+	}
+	qualifyDependencies := func(dependsOn []string) {
+		for idx, dependency := range dependsOn {
+			if local[dependency] {
+				dependsOn[idx] = qualify(dependency)
+			}
+		}
+	}
+
+	for idx := range fullSchema.Tables {
+		table := &fullSchema.Tables[idx]
+		table.Name = qualify(table.Name)
+		table.Schema = schema
+		qualifyColumns(table.KeyColumns)
+		qualifyColumns(table.Columns)
+		for fkIdx, fk := range table.ForeignKeys {
+			if fk.RefSchema == "" {
+				table.ForeignKeys[fkIdx].RefTable = qualify(fk.RefTable)
+			}
+		}
+		table.PartitionOf = qualify(table.PartitionOf)
+		for partIdx, partition := range table.Partitions {
+			table.Partitions[partIdx] = qualify(partition)
+		}
+	}
+	for idx := range fullSchema.Views {
+		fullSchema.Views[idx].Name = qualify(fullSchema.Views[idx].Name)
+		qualifyColumns(fullSchema.Views[idx].Columns)
+		qualifyDependencies(fullSchema.Views[idx].DependsOn)
+	}
+	for idx := range fullSchema.MaterializedViews {
+		fullSchema.MaterializedViews[idx].Name = qualify(fullSchema.MaterializedViews[idx].Name)
+		qualifyColumns(fullSchema.MaterializedViews[idx].Columns)
+		qualifyDependencies(fullSchema.MaterializedViews[idx].DependsOn)
+	}
+	for idx := range fullSchema.Enums {
+		fullSchema.Enums[idx].Name = qualify(fullSchema.Enums[idx].Name)
+	}
+	for idx := range fullSchema.Domains {
+		fullSchema.Domains[idx].Name = qualify(fullSchema.Domains[idx].Name)
+	}
+	for idx := range fullSchema.Types {
+		fullSchema.Types[idx].Name = qualify(fullSchema.Types[idx].Name)
+		qualifyColumns(fullSchema.Types[idx].Attributes)
+	}
+	for idx := range fullSchema.Functions {
+		fullSchema.Functions[idx].Name = qualify(fullSchema.Functions[idx].Name)
+	}
+	for idx := range fullSchema.Sequences {
+		fullSchema.Sequences[idx].Name = qualify(fullSchema.Sequences[idx].Name)
+		fullSchema.Sequences[idx].OwnedBy = qualify(fullSchema.Sequences[idx].OwnedBy)
+	}
+}
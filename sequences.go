@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Sequence is a sequence in the schema, standalone or backing a serial or
+// identity column
+type Sequence struct {
+	Name      string `json:"name"`
+	DataType  string `json:"type"`
+	Start     int64  `json:"start"`
+	Increment int64  `json:"increment"`
+	Min       int64  `json:"min"`
+	Max       int64  `json:"max"`
+	Cycle     bool   `json:"cycle"`
+
+	// LastValue is nil until the sequence has been used, or when this role
+	// can't read it
+	LastValue *int64 `json:"lastValue,omitempty"`
+
+	// OwnedBy is the table.column the sequence belongs to, dropped with it
+	OwnedBy string `json:"ownedBy,omitempty"`
+
+	// Identity is true when the sequence backs an identity column rather
+	// than a serial one
+	Identity bool `json:"identity"`
+}
+
+// getSequences reads the sequences in the schema, and marks the columns
+// which own one with its name
+func getSequences(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) ([]Sequence, error) {
+	version, err := serverVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if version < 100000 {
+		report.warn(warnSkipped, "sequences", "pg_sequences needs Postgres 10 or later")
+		return nil, nil
+	}
+
+	// An 'a'uto dependency is a serial's OWNED BY, 'i'nternal an identity
+	rows, err := db.QueryRaw(ctx, `SELECT s.sequencename, s.data_type::text,
+	s.start_value, s.increment_by, s.min_value, s.max_value, s.cycle, s.last_value,
+	COALESCE(t.relname, ''), COALESCE(a.attname, ''), COALESCE(d.deptype = 'i', false)
+	FROM pg_catalog.pg_sequences s
+	JOIN pg_catalog.pg_namespace n ON n.nspname = s.schemaname
+	JOIN pg_catalog.pg_class c ON c.relname = s.sequencename AND c.relnamespace = n.oid
+	LEFT JOIN pg_catalog.pg_depend d ON d.classid = 'pg_catalog.pg_class'::regclass
+		AND d.objid = c.oid
+		AND d.refclassid = 'pg_catalog.pg_class'::regclass
+		AND d.deptype IN ('a', 'i')
+	LEFT JOIN pg_catalog.pg_class t ON t.oid = d.refobjid
+	LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+	WHERE s.schemaname = $1
+	ORDER BY s.sequencename`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	sequences := []Sequence{}
+	for rows.Next() {
+		seq := Sequence{}
+		var tableName, columnName string
+		if err := rows.Scan(&seq.Name, &seq.DataType, &seq.Start, &seq.Increment, &seq.Min, &seq.Max, &seq.Cycle, &seq.LastValue, &tableName, &columnName, &seq.Identity); err != nil {
+			return nil, err
+		}
+		if tableName != "" && columnName != "" {
+			seq.OwnedBy = tableName + "." + columnName
+			if table, ok := byName[tableName]; ok {
+				markSequence(table.KeyColumns, columnName, seq.Name)
+				markSequence(table.Columns, columnName, seq.Name)
+			}
+		}
+		sequences = append(sequences, seq)
+	}
+	return sequences, rows.Err()
+}
+
+func markSequence(columns []ColumnDefinition, name string, sequence string) {
+	for idx := range columns {
+		if columns[idx].Name == name {
+			columns[idx].Sequence = sequence
+		}
+	}
+}
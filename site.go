@@ -0,0 +1,228 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// sitePage is the data for one page of the static site. Root is the relative
+// path back to the site's index, so pages can be moved as a whole.
+type sitePage struct {
+	Root   string
+	Title  string
+	Schema *Schema
+	Table  *Table
+	Enum   *Enum
+
+	// ReferencedBy are the tables with a foreign key to Table
+	ReferencedBy []Table
+}
+
+// siteDump writes a static HTML site to dir, an index plus one page per
+// table in tables/ and per enum in enums/
+func siteDump(schema *Schema, dir string, options MarkdownOptions) error {
+	enums := map[string]bool{}
+	for _, enum := range schema.Enums {
+		enums[enum.Name] = true
+	}
+
+	funcs := htmlFuncs(options)
+	funcs["tableHref"] = func(name string) string {
+		return "tables/" + url.PathEscape(name) + ".html"
+	}
+	funcs["enumHref"] = func(name string) string {
+		return "enums/" + url.PathEscape(name) + ".html"
+	}
+	funcs["isEnum"] = func(name string) bool {
+		return enums[name]
+	}
+
+	tpl, err := template.New("site").Funcs(funcs).Parse(siteTemplate)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range []string{"tables", "enums"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	write := func(filename string, page sitePage) error {
+		return withWriter(filepath.Join(dir, filepath.FromSlash(filename)), options.decoratedWithin("<body>", "</body>", func(w io.Writer) error {
+			return tpl.ExecuteTemplate(w, "page", page)
+		}))
+	}
+
+	if err := write("index.html", sitePage{Title: "Schema", Schema: schema}); err != nil {
+		return err
+	}
+
+	written := map[string]bool{"index.html": true}
+	for idx := range schema.Tables {
+		table := &schema.Tables[idx]
+		page := sitePage{Root: "../", Title: table.Name, Schema: schema, Table: table}
+		for _, other := range schema.Tables {
+			for _, fk := range other.ForeignKeys {
+				if fk.RefTable == table.Name {
+					page.ReferencedBy = append(page.ReferencedBy, other)
+					break
+				}
+			}
+		}
+		filename := "tables/" + table.Name + ".html"
+		written[filename] = true
+		if err := write(filename, page); err != nil {
+			return err
+		}
+	}
+
+	for idx := range schema.Enums {
+		enum := &schema.Enums[idx]
+		page := sitePage{Root: "../", Title: enum.Name, Schema: schema, Enum: enum}
+		filename := "enums/" + enum.Name + ".html"
+		written[filename] = true
+		if err := write(filename, page); err != nil {
+			return err
+		}
+	}
+
+	// Pages of tables and enums since dropped would otherwise linger
+	return removeStalePages(dir, written)
+}
+
+var siteTemplate = `
+{{- define "page" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { margin: 0; font-family: sans-serif; display: flex; }
+nav { position: sticky; top: 0; height: 100vh; overflow-y: auto; width: 16em; flex-shrink: 0; padding: 1em; box-sizing: border-box; background: #f4f4f4; }
+nav ul { list-style: none; padding: 0; }
+main { padding: 1em 2em; flex-grow: 1; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<nav>
+<a href="{{ .Root }}index.html">Index</a>
+<h3>Tables</h3>
+<ul>
+{{- range .Schema.Tables }}
+<li><a href="{{ $.Root }}{{ tableHref .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- if .Schema.Enums }}
+<h3>Enums</h3>
+<ul>
+{{- range .Schema.Enums }}
+<li><a href="{{ $.Root }}{{ enumHref .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+</nav>
+<main>
+{{- if .Table }}{{ template "table" . }}{{ else if .Enum }}{{ template "enum" . }}{{ else }}{{ template "index" . }}{{ end }}
+</main>
+</body>
+</html>
+{{ end }}
+
+{{- define "index" }}
+<h1>Tables</h1>
+<table>
+<tr><th>Table</th><th>Referenced by</th><th>References</th></tr>
+{{- range .Schema.Tables }}
+<tr><td><a href="{{ tableHref .Name }}">{{ .Name }}</a></td><td>{{ .FanIn }}</td><td>{{ .FanOut }}</td></tr>
+{{- end }}
+</table>
+{{- if .Schema.Enums }}
+<h1>Enums</h1>
+<ul>
+{{- range .Schema.Enums }}
+<li><a href="{{ enumHref .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Schema.Functions }}
+<h1>Functions</h1>
+<table>
+<tr><th>Function</th><th>Returns</th><th>Language</th><th>Volatility</th><th>Description</th></tr>
+{{- range .Schema.Functions }}
+<tr><td><code>{{ .Signature }}</code></td><td><code>{{ .Returns }}</code></td><td>{{ .Language }}</td><td>{{ .Volatility }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+
+{{- define "type" }}
+{{- if and (linkable .) (isEnum .ElementType) }}<a href="../{{ enumHref .ElementType }}">{{ .DataType }}</a>{{ else }}{{ .DataType }}{{ end }}
+{{- end }}
+
+{{- define "table" }}
+{{- with .Table }}
+<h1>{{ snakeToTitle .Name }}</h1>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+<table>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+{{- range .KeyColumns }}
+<tr><td>{{ .Name }} (KEY)</td><td>{{ template "type" . }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+{{- range .Columns }}
+<tr><td>{{ .Name }}{{ if .IsKey }} (KEY){{ end }}{{ if .Generated }} (GENERATED){{ end }}</td><td>{{ template "type" . }}</td><td>{{ cellDescription .Description }}</td></tr>
+{{- end }}
+</table>
+{{- if or .ForeignKeys .Polymorphic }}
+<h2>References</h2>
+<ul>
+{{- range .ForeignKeys }}
+<li>{{ .Name }}{{ if .Logical }} (logical){{ end }}: {{ .ColumnList }} &rarr; <a href="../{{ tableHref .RefTable }}">{{ .RefTable }}</a>.{{ .RefColumnList }}</li>
+{{- end }}
+{{- range .Polymorphic }}
+<li>{{ .Name }} (polymorphic on {{ .TypeColumn }}, {{ .IDColumn }}{{ if .Targets }}: {{ range $idx, $target := .Targets }}{{ if $idx }}, {{ end }}<a href="../{{ tableHref $target }}">{{ $target }}</a>{{ end }}{{ end }})</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+{{- if .ReferencedBy }}
+<h2>Referenced By</h2>
+<ul>
+{{- range .ReferencedBy }}
+<li><a href="../{{ tableHref .Name }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+
+{{- define "enum" }}
+{{- with .Enum }}
+<h1>{{ snakeToTitle .Name }}</h1>
+{{- with .Description }}
+{{ description . }}
+{{- end }}
+{{- if .Transitions }}
+<table>
+<tr><th>From</th><th>To</th></tr>
+{{- range .Transitions }}
+<tr><td>{{ .From }}</td><td>{{ .To }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<ul>
+{{- range .Values }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+{{- end }}
+`
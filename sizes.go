@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// TableSize is how many rows a table has and how much disk it takes, in
+// bytes
+type TableSize struct {
+	// Rows is the planner's estimate, -1 when the table has never been
+	// vacuumed or analyzed
+	Rows int64 `json:"rows"`
+
+	// Table excludes the table's TOAST, which Toast has with its index
+	Table   int64 `json:"table"`
+	Indexes int64 `json:"indexes"`
+	Toast   int64 `json:"toast"`
+}
+
+// Total is the disk the table takes with its indexes and TOAST
+func (s TableSize) Total() int64 {
+	return s.Table + s.Indexes + s.Toast
+}
+
+// RowEstimate is Rows, or unknown
+func (s TableSize) RowEstimate() string {
+	if s.Rows < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d", s.Rows)
+}
+
+// byteSize formats a number of bytes in the largest unit it has at least
+// one of, like pg_size_pretty
+func byteSize(bytes int64) string {
+	units := []string{"bytes", "kB", "MB", "GB", "TB"}
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", bytes, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
+
+// largestTables returns the tables with a size, largest first
+func largestTables(schema *Schema) []Table {
+	sized := []Table{}
+	for _, table := range schema.Tables {
+		if table.Size != nil {
+			sized = append(sized, table)
+		}
+	}
+	sort.SliceStable(sized, func(i, j int) bool {
+		return sized[i].Size.Total() > sized[j].Size.Total()
+	})
+	return sized
+}
+
+// addSizes fills in each table's estimated row count and size on disk.
+// Partitioned tables hold no data themselves, so only their partitions
+// have a size.
+func addSizes(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, c.reltuples, pg_catalog.pg_table_size(c.oid), pg_catalog.pg_indexes_size(c.oid),
+		CASE WHEN c.reltoastrelid = 0 THEN 0 ELSE pg_catalog.pg_total_relation_size(c.reltoastrelid) END
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind = 'r'`, schema)
+	if err := report.optional("table sizes", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var name string
+		var reltuples float64
+		var tableSize int64
+		size := TableSize{}
+		if err := rows.Scan(&name, &reltuples, &tableSize, &size.Indexes, &size.Toast); err != nil {
+			return err
+		}
+		table, ok := byName[name]
+		if !ok {
+			continue
+		}
+		size.Rows = int64(reltuples)
+		// pg_table_size counts the TOAST table, which is kept separate
+		size.Table = tableSize - size.Toast
+		table.Size = &size
+	}
+	return rows.Err()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// SchemaSource provides the schema for the renderers, so rendering doesn't
+// need to know whether it came from a live database or a saved snapshot.
+type SchemaSource interface {
+	GetSchema() (*Schema, *Report, error)
+}
+
+// postgresSource introspects a live database
+type postgresSource struct {
+	config Config
+}
+
+func (ps postgresSource) GetSchema() (*Schema, *Report, error) {
+	return getSchema(ps.config)
+}
+
+// jsonFileSource reads a schema previously written by -json
+type jsonFileSource struct {
+	filename string
+
+	// excludeColumns are left out as they would be by introspection, see
+	// Config.ExcludeColumns
+	excludeColumns []string
+}
+
+func (js jsonFileSource) GetSchema() (*Schema, *Report, error) {
+	data, err := ioutil.ReadFile(js.filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope := schemaEnvelope{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, nil, fmt.Errorf("Reading %s: %w", js.filename, err)
+	}
+	if envelope.Version != schemaFormatVersion {
+		return nil, nil, fmt.Errorf("%s is schema format version %d, this pgdoc reads version %d", js.filename, envelope.Version, schemaFormatVersion)
+	}
+	if envelope.Schema == nil {
+		return nil, nil, fmt.Errorf("%s has no schema", js.filename)
+	}
+	upgradeSnapshot(envelope.Schema)
+	excludeColumns(envelope.Schema, js.excludeColumns)
+	report := newReport()
+	report.analyse(envelope.Schema)
+	return envelope.Schema, report, nil
+}
+
+// upgradeSnapshot fills in what snapshots written by older versions lack,
+// where it follows from what they have, so they needn't be rejected. Foreign
+// keys had just Column and RefColumn before composite keys were documented.
+func upgradeSnapshot(schema *Schema) {
+	for tableIdx := range schema.Tables {
+		fks := schema.Tables[tableIdx].ForeignKeys
+		for idx, fk := range fks {
+			if len(fk.Columns) == 0 && fk.Column != "" {
+				fks[idx].Columns = []string{fk.Column}
+				fks[idx].RefColumns = []string{fk.RefColumn}
+			}
+		}
+	}
+}
+
+// schemaFormatVersion is bumped whenever the JSON output changes in a way
+// older readers can't load. Additions older snapshots can be brought up to
+// date with are made in upgradeSnapshot instead.
+const schemaFormatVersion = 1
+
+// schemaEnvelope wraps the JSON output so saved snapshots can be checked
+// before they are loaded again
+type schemaEnvelope struct {
+	Version int     `json:"version"`
+	Schema  *Schema `json:"schema"`
+}
+
+func jsonDump(schema *Schema, w io.Writer) error {
+	bytes, err := json.MarshalIndent(schemaEnvelope{
+		Version: schemaFormatVersion,
+		Schema:  schema,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(bytes); err != nil {
+		return err
+	}
+	return nil
+}
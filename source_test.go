@@ -0,0 +1,35 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestJSONFileSourceSingleColumnForeignKeys loads a snapshot saved before
+// foreign keys had Columns and RefColumns
+func TestJSONFileSourceSingleColumnForeignKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgdoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(filename, []byte(`{"version": 1, "schema": {"Tables": [{
+		"Name": "users",
+		"ForeignKeys": [{"Column": "org_id", "Name": "users_org_id_fkey", "RefTable": "orgs", "RefColumn": "id"}]
+	}]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, _, err := jsonFileSource{filename: filename}.GetSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fk := schema.Tables[0].ForeignKeys[0]
+	if !reflect.DeepEqual(fk.Columns, []string{"org_id"}) || !reflect.DeepEqual(fk.RefColumns, []string{"id"}) {
+		t.Errorf("expected the columns org_id -> id, got %v -> %v", fk.Columns, fk.RefColumns)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+
+	sq "github.com/elgris/sqrl"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteCatalogSchema is the layout of the -sqlite output. Queries are
+// written against it by users, so change it only by adding things.
+const sqliteCatalogSchema = `
+CREATE TABLE tables (
+	name TEXT NOT NULL PRIMARY KEY,
+	description TEXT NOT NULL
+);
+
+CREATE TABLE columns (
+	table_name TEXT NOT NULL REFERENCES tables(name),
+	name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	data_type TEXT NOT NULL,
+	custom_type BOOLEAN NOT NULL,
+	nullable BOOLEAN NOT NULL,
+	is_key BOOLEAN NOT NULL,
+	description TEXT NOT NULL,
+	generated TEXT NOT NULL,
+	PRIMARY KEY (table_name, name)
+);
+
+CREATE TABLE constraints (
+	table_name TEXT NOT NULL REFERENCES tables(name),
+	name TEXT NOT NULL,
+	constraint_type TEXT NOT NULL,
+	column_name TEXT NOT NULL
+);
+
+CREATE TABLE foreign_keys (
+	table_name TEXT NOT NULL REFERENCES tables(name),
+	name TEXT NOT NULL,
+	column_name TEXT NOT NULL,
+	ref_table TEXT NOT NULL,
+	ref_column TEXT NOT NULL
+);
+
+CREATE TABLE enums (
+	name TEXT NOT NULL PRIMARY KEY,
+	description TEXT NOT NULL
+);
+
+CREATE TABLE enum_values (
+	enum_name TEXT NOT NULL REFERENCES enums(name),
+	position INTEGER NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (enum_name, value)
+);
+
+CREATE TABLE functions (
+	name TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	arguments TEXT NOT NULL,
+	returns TEXT NOT NULL,
+	language TEXT NOT NULL,
+	volatility TEXT NOT NULL,
+	description TEXT NOT NULL,
+	PRIMARY KEY (name, arguments)
+);
+`
+
+// sqliteDump writes the schema into a fresh SQLite database at filename so
+// it can be queried with SQL. An existing file is replaced.
+func sqliteDump(schema *Schema, filename string) error {
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	conn, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteCatalogSchema); err != nil {
+		return err
+	}
+
+	insert := func(builder *sq.InsertBuilder) error {
+		stmt, args, err := builder.ToSql()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(stmt, args...)
+		return err
+	}
+
+	for _, table := range schema.Tables {
+		if err := insert(sq.Insert("tables").
+			Columns("name", "description").
+			Values(table.Name, table.Description)); err != nil {
+			return err
+		}
+
+		for _, col := range table.allColumns() {
+			if err := insert(sq.Insert("columns").
+				Columns("table_name", "name", "position", "data_type", "custom_type", "nullable", "is_key", "description", "generated").
+				Values(table.Name, col.Name, col.Ordinal, col.DataType, col.CustomType, col.IsNullable, col.IsKey, col.Description, col.Generated)); err != nil {
+				return err
+			}
+			if col.IsKey {
+				if err := insert(sq.Insert("constraints").
+					Columns("table_name", "name", "constraint_type", "column_name").
+					Values(table.Name, table.PrimaryKey, "PRIMARY KEY", col.Name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, fk := range table.ForeignKeys {
+			for colIdx, col := range fk.Columns {
+				if err := insert(sq.Insert("constraints").
+					Columns("table_name", "name", "constraint_type", "column_name").
+					Values(table.Name, fk.Name, "FOREIGN KEY", col)); err != nil {
+					return err
+				}
+				if err := insert(sq.Insert("foreign_keys").
+					Columns("table_name", "name", "column_name", "ref_table", "ref_column").
+					Values(table.Name, fk.Name, col, fk.RefTable, fk.RefColumns[colIdx])); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, enum := range schema.Enums {
+		if err := insert(sq.Insert("enums").
+			Columns("name", "description").
+			Values(enum.Name, enum.Description)); err != nil {
+			return err
+		}
+		for idx, value := range enum.Values {
+			if err := insert(sq.Insert("enum_values").
+				Columns("enum_name", "position", "value").
+				Values(enum.Name, idx+1, value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, function := range schema.Functions {
+		if err := insert(sq.Insert("functions").
+			Columns("name", "kind", "arguments", "returns", "language", "volatility", "description").
+			Values(function.Name, function.Kind, function.Arguments, function.Returns, function.Language, function.Volatility, function.Description)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
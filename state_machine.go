@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transition is an allowed change between two values of an enum which
+// models a state machine
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// addTransitions parses state machine transitions declared in enum comments,
+// one line per state:
+//
+//	pending -> paid, cancelled
+//	paid -> refunded
+//
+// The declaration lines are removed from the description. Transitions between
+// values the enum doesn't have are warned about and dropped.
+func addTransitions(schema *Schema) {
+	for idx, enum := range schema.Enums {
+		values := map[string]bool{}
+		for _, value := range enum.Values {
+			values[value] = true
+		}
+
+		transitions := []Transition{}
+		description := []string{}
+		for _, line := range strings.Split(enum.Description, "\n") {
+			parts := strings.SplitN(line, "->", 2)
+			if len(parts) != 2 {
+				description = append(description, line)
+				continue
+			}
+			from := strings.TrimSpace(parts[0])
+			for _, to := range strings.Split(parts[1], ",") {
+				to = strings.TrimSpace(to)
+				if !values[from] || !values[to] {
+					warnf("enum %s: transition %s -> %s is between unknown values", enum.Name, from, to)
+					continue
+				}
+				transitions = append(transitions, Transition{From: from, To: to})
+			}
+		}
+		if len(transitions) == 0 {
+			continue
+		}
+		schema.Enums[idx].Transitions = transitions
+		schema.Enums[idx].Description = strings.TrimSpace(strings.Join(description, "\n"))
+	}
+}
+
+// StateDiagram renders the enum's transitions as a PlantUML state diagram.
+// The first value is the initial state and values with no way out are final.
+func (e Enum) StateDiagram() string {
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "@startuml")
+	if len(e.Values) > 0 {
+		fmt.Fprintf(out, "[*] --> %s\n", e.Values[0])
+	}
+	hasExit := map[string]bool{}
+	for _, transition := range e.Transitions {
+		fmt.Fprintf(out, "%s --> %s\n", transition.From, transition.To)
+		hasExit[transition.From] = true
+	}
+	for _, value := range e.Values {
+		if !hasExit[value] {
+			fmt.Fprintf(out, "%s --> [*]\n", value)
+		}
+	}
+	fmt.Fprintln(out, "@enduml")
+	return out.String()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// commonValuesShown is how many of a column's most common values markdown
+// lists, JSON has them all
+const commonValuesShown = 5
+
+// ColumnStats are the planner's statistics on a column's data, as of the
+// last ANALYZE
+type ColumnStats struct {
+	// NullFraction is the fraction of rows which are null
+	NullFraction float64 `json:"nullFraction"`
+
+	// Distinct is the estimated number of distinct values or, when
+	// negative, minus that number as a fraction of the rows, for columns
+	// expected to grow with the table
+	Distinct float64 `json:"distinct"`
+
+	// MostCommonValues are in order of their frequencies, as text
+	MostCommonValues      []string  `json:"mostCommonValues,omitempty"`
+	MostCommonFrequencies []float64 `json:"mostCommonFrequencies,omitempty"`
+}
+
+// NullPercent is NullFraction as a percentage
+func (s ColumnStats) NullPercent() string {
+	return fmt.Sprintf("%.1f%%", s.NullFraction*100)
+}
+
+// DistinctEstimate describes Distinct, as either a number of values or a
+// share of the rows
+func (s ColumnStats) DistinctEstimate() string {
+	switch {
+	case s.Distinct < 0:
+		return fmt.Sprintf("%.1f%% of rows", -s.Distinct*100)
+	case s.Distinct == 0:
+		return "unknown"
+	default:
+		return fmt.Sprintf("%.0f", s.Distinct)
+	}
+}
+
+// CommonValues lists the first of the most common values with their
+// frequencies
+func (s ColumnStats) CommonValues() []string {
+	values := []string{}
+	for idx, value := range s.MostCommonValues {
+		if idx == commonValuesShown {
+			break
+		}
+		if idx < len(s.MostCommonFrequencies) {
+			value = fmt.Sprintf("%s (%.1f%%)", value, s.MostCommonFrequencies[idx]*100)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// HasStats is true when any of the table's columns have statistics
+func (t Table) HasStats() bool {
+	for _, col := range t.allColumns() {
+		if col.Stats != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addColumnStats fills in each column's statistics from pg_stats, which
+// only has the columns the current user can read, and only once the table
+// has been analyzed. The most common values of redacted columns are left
+// out.
+func addColumnStats(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, redact []string, report *Report) error {
+	// Inherited statistics cover a parent and its children, those of the
+	// table alone are wanted
+	rows, err := db.QueryRaw(ctx, `SELECT tablename, attname, null_frac, n_distinct,
+		COALESCE(most_common_vals::text::text[], '{}'), COALESCE(most_common_freqs, '{}')
+	FROM pg_catalog.pg_stats
+	WHERE schemaname = $1 AND NOT inherited`, schema)
+	if err := report.optional("column statistics", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var tableName, columnName string
+		stats := ColumnStats{}
+		values := pq.StringArray{}
+		frequencies := pq.Float64Array{}
+		if err := rows.Scan(&tableName, &columnName, &stats.NullFraction, &stats.Distinct, &values, &frequencies); err != nil {
+			return err
+		}
+		table, ok := byName[tableName]
+		if !ok {
+			continue
+		}
+		if !columnListed(redact, tableName, columnName) {
+			stats.MostCommonValues = values
+			stats.MostCommonFrequencies = frequencies
+		}
+		for _, cols := range [][]ColumnDefinition{table.KeyColumns, table.Columns} {
+			for idx := range cols {
+				if cols[idx].Name == columnName {
+					colStats := stats
+					cols[idx].Stats = &colStats
+				}
+			}
+		}
+	}
+	return rows.Err()
+}
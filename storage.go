@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lib/pq"
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// defaultAccessMethod is the access method of a table created without USING
+const defaultAccessMethod = "heap"
+
+// HasCustomStorage is true when the table doesn't use the default storage,
+// which is the only time it is worth documenting
+func (t Table) HasCustomStorage() bool {
+	return (t.AccessMethod != "" && t.AccessMethod != defaultAccessMethod) || len(t.StorageParams) > 0 || t.Tablespace != ""
+}
+
+// addStorage fills in each table's access method, tablespace and storage
+// parameters
+func addStorage(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	version, err := serverVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	// Table access methods arrived in Postgres 12, before then everything
+	// was heap
+	accessMethod, amJoin := "''", ""
+	if version >= 120000 {
+		accessMethod = "COALESCE(am.amname, '')"
+		amJoin = "LEFT JOIN pg_catalog.pg_am am ON am.oid = c.relam"
+	}
+
+	// reltablespace is 0 for the database's default tablespace
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, `+accessMethod+`, COALESCE(c.reloptions, '{}'), COALESCE(ts.spcname, '')
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_tablespace ts ON ts.oid = c.reltablespace
+	`+amJoin+`
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'm', 'p')`, schema)
+	if err := report.optional("storage parameters", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var name, am, tablespace string
+		options := pq.StringArray{}
+		if err := rows.Scan(&name, &am, &options, &tablespace); err != nil {
+			return err
+		}
+		table, ok := byName[name]
+		if !ok {
+			continue
+		}
+		table.AccessMethod = am
+		table.Tablespace = tablespace
+		for _, option := range options {
+			parts := strings.SplitN(option, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if table.StorageParams == nil {
+				table.StorageParams = map[string]string{}
+			}
+			table.StorageParams[parts[0]] = parts[1]
+		}
+	}
+	return rows.Err()
+}
+
+// serverVersion returns the server's version as a number, e.g. 120004
+func serverVersion(ctx context.Context, db *sqrlx.Wrapper) (int, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT current_setting('server_version_num')::int`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	version := 0
+	for rows.Next() {
+		if err := rows.Scan(&version); err != nil {
+			return 0, err
+		}
+	}
+	return version, rows.Err()
+}
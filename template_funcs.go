@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// typeKindNames name each typeKind for the typeKind template func
+var typeKindNames = map[typeKind]string{
+	kindString:     "string",
+	kindInteger:    "integer",
+	kindBigInteger: "bigint",
+	kindFloat:      "float",
+	kindDecimal:    "decimal",
+	kindBoolean:    "boolean",
+	kindTimestamp:  "timestamp",
+	kindDate:       "date",
+	kindTime:       "time",
+	kindUUID:       "uuid",
+	kindJSON:       "json",
+	kindBytes:      "bytes",
+	kindArray:      "array",
+}
+
+// templateFuncs are the general purpose helpers of the markdown templates,
+// for -md-template. Those shared with sprig take their arguments in the same
+// order, the value last, so they work in pipelines like
+// {{ .Name | trimPrefix "tbl_" | upper }}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      titleWords,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix string, val string) string { return strings.TrimPrefix(val, prefix) },
+		"trimSuffix": func(suffix string, val string) string { return strings.TrimSuffix(val, suffix) },
+		"replace":    func(old string, new string, val string) string { return strings.ReplaceAll(val, old, new) },
+		"contains":   func(substr string, val string) bool { return strings.Contains(val, substr) },
+		"hasPrefix":  func(prefix string, val string) bool { return strings.HasPrefix(val, prefix) },
+		"hasSuffix":  func(suffix string, val string) bool { return strings.HasSuffix(val, suffix) },
+		"split":      func(sep string, val string) []string { return strings.Split(val, sep) },
+		"repeat":     func(count int, val string) string { return strings.Repeat(val, count) },
+		"trunc":      truncate,
+		"indent":     indent,
+		"quote":      func(val string) string { return fmt.Sprintf("%q", val) },
+		"code":       func(val string) string { return "`" + val + "`" },
+		"firstLine":  func(val string) string { return strings.SplitN(val, "\n", 2)[0] },
+		"default":    defaultValue,
+
+		// Case conversion of snake_case names
+		"camelCase":  func(val string) string { return camelCase(val, false) },
+		"pascalCase": func(val string) string { return camelCase(val, true) },
+		"kebabCase":  func(val string) string { return strings.ReplaceAll(val, "_", "-") },
+
+		// Lists, of any kind of slice
+		"list":      func(items ...interface{}) []interface{} { return items },
+		"first":     func(list interface{}) interface{} { return listItem(list, 0) },
+		"last":      func(list interface{}) interface{} { return listItem(list, -1) },
+		"rest":      listRest,
+		"has":       listHas,
+		"uniq":      listUniq,
+		"sortAlpha": sortAlpha,
+
+		// Dicts, string keyed maps
+		"dict":   dict,
+		"get":    func(d map[string]interface{}, key string) interface{} { return d[key] },
+		"set":    dictSet,
+		"hasKey": func(d map[string]interface{}, key string) bool { _, ok := d[key]; return ok },
+		"keys":   dictKeys,
+
+		// Arithmetic, for counters and widths
+		"add": func(a int, b int) int { return a + b },
+		"sub": func(a int, b int) int { return a - b },
+
+		// Type mapping, of a column's DataType
+		"typeKind":    func(dataType string) string { return typeKindNames[dataTypeKind(dataType)] },
+		"goType":      goTypeName,
+		"tsType":      typescriptTypeName,
+		"graphqlType": graphqlTypeName,
+
+		// Links, with the href from anchor, typeHref or tableHref
+		"link": func(text string, href string) string { return "[" + text + "](" + href + ")" },
+
+		"plural": plural,
+	}
+}
+
+// titleWords capitalises the first letter of each word
+func titleWords(val string) string {
+	words := strings.Split(val, " ")
+	for idx, word := range words {
+		if word != "" {
+			words[idx] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// truncate cuts val to length runes
+func truncate(length int, val string) string {
+	runes := []rune(val)
+	if length < 0 || len(runes) <= length {
+		return val
+	}
+	return string(runes[:length])
+}
+
+// indent indents every line of val by spaces
+func indent(spaces int, val string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(val, "\n", "\n"+pad)
+}
+
+// defaultValue is val, or fallback when val is empty, like the empty
+// description of a table
+func defaultValue(fallback interface{}, val interface{}) interface{} {
+	if val == nil {
+		return fallback
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return fallback
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return fallback
+		}
+	case reflect.Bool:
+		if !v.Bool() {
+			return fallback
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			return fallback
+		}
+	}
+	return val
+}
+
+// toList turns any slice into a list of its items, or nil for anything else
+func toList(list interface{}) []interface{} {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	items := make([]interface{}, v.Len())
+	for idx := range items {
+		items[idx] = v.Index(idx).Interface()
+	}
+	return items
+}
+
+// listItem is the item at idx, counting from the end when negative, or nil
+// when there isn't one
+func listItem(list interface{}, idx int) interface{} {
+	items := toList(list)
+	if idx < 0 {
+		idx += len(items)
+	}
+	if idx < 0 || idx >= len(items) {
+		return nil
+	}
+	return items[idx]
+}
+
+func listRest(list interface{}) []interface{} {
+	items := toList(list)
+	if len(items) == 0 {
+		return items
+	}
+	return items[1:]
+}
+
+func listHas(needle interface{}, list interface{}) bool {
+	for _, item := range toList(list) {
+		if reflect.DeepEqual(item, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func listUniq(list interface{}) []interface{} {
+	uniq := []interface{}{}
+	for _, item := range toList(list) {
+		if !listHas(item, uniq) {
+			uniq = append(uniq, item)
+		}
+	}
+	return uniq
+}
+
+// sortAlpha sorts the items of a list as text
+func sortAlpha(list interface{}) []string {
+	sorted := []string{}
+	for _, item := range toList(list) {
+		sorted = append(sorted, fmt.Sprint(item))
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// dict makes a map of alternating keys and values
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict needs a value for every key")
+	}
+	d := map[string]interface{}{}
+	for idx := 0; idx < len(pairs); idx += 2 {
+		d[fmt.Sprint(pairs[idx])] = pairs[idx+1]
+	}
+	return d, nil
+}
+
+// dictSet sets a key of a dict and returns the dict, as in sprig
+func dictSet(d map[string]interface{}, key string, val interface{}) map[string]interface{} {
+	d[key] = val
+	return d
+}
+
+func dictKeys(d map[string]interface{}) []string {
+	keys := []string{}
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// goTypeName is the Go type -go gives a column of the data type, without
+// the pointer of nullable columns or the named type of enums
+func goTypeName(dataType string) string {
+	if mapped, ok := goTypes[dataTypeKind(dataType)]; ok {
+		return mapped
+	}
+	return "string"
+}
+
+// typescriptTypeName is the TypeScript type -typescript gives a column of
+// the data type, without the union of enums
+func typescriptTypeName(dataType string) string {
+	if mapped, ok := typescriptTypes[dataTypeKind(dataType)]; ok {
+		return mapped
+	}
+	return "string"
+}
+
+// graphqlTypeName is the GraphQL type -graphql gives a column of the data
+// type, without the ! of required columns
+func graphqlTypeName(dataType string) string {
+	kind := dataTypeKind(dataType)
+	switch kind {
+	case kindInteger:
+		return "Int"
+	case kindFloat:
+		return "Float"
+	case kindBoolean:
+		return "Boolean"
+	case kindArray:
+		return "[String]"
+	}
+	if scalar, ok := graphqlScalars[kind]; ok {
+		return scalar
+	}
+	return "String"
+}
+
+// plural is the plural of an English word, unless count is one, as in
+// {{ len .Columns }} {{ plural (len .Columns) "column" }}
+func plural(count int, word string) string {
+	if count == 1 || word == "" {
+		return word
+	}
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou"):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// Trigger is a user defined trigger on a table
+type Trigger struct {
+	Name string `json:"name"`
+
+	// Timing is BEFORE, AFTER or INSTEAD OF, Level ROW or STATEMENT
+	Timing string   `json:"timing"`
+	Events []string `json:"events"`
+	Level  string   `json:"level"`
+
+	// Function is schema qualified when it's outside the table's schema
+	Function string `json:"function"`
+
+	// Enabled is origin, replica, always or disabled, which is when the
+	// trigger fires given session_replication_role
+	Enabled string `json:"enabled"`
+
+	// Definition is the CREATE TRIGGER statement, which includes any WHEN
+	// condition
+	Definition string `json:"definition"`
+}
+
+// tgtype bits, from pg_trigger.h
+const (
+	triggerTypeRow      = 1 << 0
+	triggerTypeBefore   = 1 << 1
+	triggerTypeInsert   = 1 << 2
+	triggerTypeDelete   = 1 << 3
+	triggerTypeUpdate   = 1 << 4
+	triggerTypeTruncate = 1 << 5
+	triggerTypeInstead  = 1 << 6
+)
+
+var triggerEnabled = map[string]string{
+	"O": "origin",
+	"R": "replica",
+	"A": "always",
+	"D": "disabled",
+}
+
+// addTriggers fills in each table's triggers, leaving out the internal
+// ones postgres creates for foreign keys
+func addTriggers(ctx context.Context, db *sqrlx.Wrapper, schema string, tables []Table, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, t.tgname, t.tgtype, t.tgenabled::text,
+	CASE WHEN pn.nspname = $1 THEN p.proname::text ELSE pn.nspname || '.' || p.proname END,
+	pg_catalog.pg_get_triggerdef(t.oid, true)
+	FROM pg_catalog.pg_trigger t
+	JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_catalog.pg_proc p ON p.oid = t.tgfoid
+	JOIN pg_catalog.pg_namespace pn ON pn.oid = p.pronamespace
+	WHERE n.nspname = $1 AND NOT t.tgisinternal
+	ORDER BY c.relname, t.tgname`, schema)
+	if err := report.optional("triggers", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*Table{}
+	for idx := range tables {
+		byName[tables[idx].Name] = &tables[idx]
+	}
+
+	for rows.Next() {
+		var tableName, enabled string
+		var tgtype int
+		trigger := Trigger{}
+		if err := rows.Scan(&tableName, &trigger.Name, &tgtype, &enabled, &trigger.Function, &trigger.Definition); err != nil {
+			return err
+		}
+
+		switch {
+		case tgtype&triggerTypeInstead != 0:
+			trigger.Timing = "INSTEAD OF"
+		case tgtype&triggerTypeBefore != 0:
+			trigger.Timing = "BEFORE"
+		default:
+			trigger.Timing = "AFTER"
+		}
+		trigger.Level = "STATEMENT"
+		if tgtype&triggerTypeRow != 0 {
+			trigger.Level = "ROW"
+		}
+		for _, event := range []struct {
+			bit  int
+			name string
+		}{
+			{triggerTypeInsert, "INSERT"},
+			{triggerTypeUpdate, "UPDATE"},
+			{triggerTypeDelete, "DELETE"},
+			{triggerTypeTruncate, "TRUNCATE"},
+		} {
+			if tgtype&event.bit != 0 {
+				trigger.Events = append(trigger.Events, event.name)
+			}
+		}
+		trigger.Enabled = triggerEnabled[enabled]
+
+		if table, ok := byName[tableName]; ok {
+			table.Triggers = append(table.Triggers, trigger)
+		}
+	}
+	return rows.Err()
+}
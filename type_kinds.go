@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+)
+
+// typeKind groups postgres data types by how the code generating outputs
+// represent them
+type typeKind int
+
+const (
+	kindString typeKind = iota
+	kindInteger
+	kindBigInteger
+	kindFloat
+	kindDecimal
+	kindBoolean
+	kindTimestamp
+	kindDate
+	kindTime
+	kindUUID
+	kindJSON
+	kindBytes
+	kindArray
+)
+
+// dataTypeKind returns the kind of a column's DataType as getColumns
+// reports it. Anything unrecognised, custom types included, is a string.
+func dataTypeKind(dataType string) typeKind {
+	switch {
+	case dataType == "smallint", dataType == "integer":
+		return kindInteger
+	case dataType == "bigint":
+		return kindBigInteger
+	case dataType == "real", dataType == "double precision":
+		return kindFloat
+	case strings.HasPrefix(dataType, "Number("), dataType == "money":
+		return kindDecimal
+	case dataType == "boolean":
+		return kindBoolean
+	case strings.HasPrefix(dataType, "timestamp"):
+		return kindTimestamp
+	case dataType == "date":
+		return kindDate
+	case strings.HasPrefix(dataType, "time "), dataType == "time":
+		return kindTime
+	case dataType == "uuid":
+		return kindUUID
+	case dataType == "json", dataType == "jsonb":
+		return kindJSON
+	case dataType == "bytea":
+		return kindBytes
+	case strings.HasSuffix(dataType, "[]"):
+		return kindArray
+	default:
+		return kindString
+	}
+}
+
+// enumsByName indexes the schema's enums, for outputs which render enum
+// typed columns differently to other custom types
+func enumsByName(schema *Schema) map[string]Enum {
+	enums := map[string]Enum{}
+	for _, enum := range schema.Enums {
+		enums[enum.Name] = enum
+	}
+	return enums
+}
+
+// camelCase converts snake_case to camelCase, or PascalCase when upper is
+// set
+func camelCase(val string, upper bool) string {
+	words := strings.FieldsFunc(val, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	out := &strings.Builder{}
+	for idx, word := range words {
+		if idx > 0 || upper {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		out.WriteString(word)
+	}
+	return out.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// typescriptIdentifier matches property names which needn't be quoted
+var typescriptIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// typescriptTypes are the types of each kind as rows arrive as JSON, so
+// big integers, numerics and timestamps are strings. Kinds missing here are
+// strings too.
+var typescriptTypes = map[typeKind]string{
+	kindInteger: "number",
+	kindFloat:   "number",
+	kindBoolean: "boolean",
+	kindJSON:    "unknown",
+	kindArray:   "unknown[]",
+}
+
+// typescriptDump renders the schema as TypeScript declarations, an
+// interface per table and a union of string literals per enum
+func typescriptDump(schema *Schema, w io.Writer) error {
+	enums := enumsByName(schema)
+	out := &strings.Builder{}
+
+	for _, table := range schema.Tables {
+		typescriptComment(out, "", table.Description)
+		fmt.Fprintf(out, "export interface %s {\n", camelCase(table.Name, true))
+		for _, col := range table.allColumns() {
+			tsType := typescriptTypeName(col.DataType)
+			if _, ok := enums[col.DataType]; ok {
+				tsType = camelCase(col.DataType, true)
+			}
+			if col.IsNullable {
+				tsType += " | null"
+			}
+			name := col.Name
+			if !typescriptIdentifier.MatchString(name) {
+				name = strconv.Quote(name)
+			}
+			// Generated columns can't be written
+			if col.Generated != "" {
+				name = "readonly " + name
+			}
+			typescriptComment(out, "  ", col.Description)
+			fmt.Fprintf(out, "  %s: %s;\n", name, tsType)
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+
+	for _, enum := range schema.Enums {
+		values := []string{}
+		for _, value := range enum.Values {
+			values = append(values, strconv.Quote(value))
+		}
+		if len(values) == 0 {
+			values = append(values, "never")
+		}
+		typescriptComment(out, "", enum.Description)
+		fmt.Fprintf(out, "export type %s = %s;\n\n", camelCase(enum.Name, true), strings.Join(values, " | "))
+	}
+
+	_, err := io.WriteString(w, strings.TrimSuffix(out.String(), "\n"))
+	return err
+}
+
+func typescriptComment(out *strings.Builder, indent string, description string) {
+	if description == "" {
+		return
+	}
+	description = strings.ReplaceAll(description, "*/", "*\\/")
+	fmt.Fprintf(out, "%s/**\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintln(out, strings.TrimRight(indent+" * "+line, " "))
+	}
+	fmt.Fprintf(out, "%s */\n", indent)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// viewColumnOrigin is the table or view column a view column selects as is
+type viewColumnOrigin struct {
+	relation    string
+	column      string
+	description string
+}
+
+// inheritViewComments gives view columns without a comment of their own the
+// comment of the column they select, noting where it came from in
+// InheritedFrom. The origins come from the target list of the view's rewrite
+// rule, which records the table and column of plain column references and
+// nothing for expressions, checked against the columns the rule depends on.
+// A view of a view is followed back to the first column with a comment.
+// Columns left out by -exclude-column are never inherited from.
+func inheritViewComments(ctx context.Context, db *sqrlx.Wrapper, schema string, views []View, matviews []MaterializedView, excludeColumns []string, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT v.relname, a.attname,
+	CASE WHEN tn.nspname = $1 THEN t.relname::text ELSE tn.nspname || '.' || t.relname END,
+	ta.attname,
+	COALESCE(pg_catalog.col_description(t.oid, ta.attnum), '')
+	FROM pg_catalog.pg_rewrite rw
+	JOIN pg_catalog.pg_class v ON v.oid = rw.ev_class
+	JOIN pg_catalog.pg_namespace vn ON vn.oid = v.relnamespace
+	CROSS JOIN LATERAL regexp_matches(rw.ev_action::text,
+		':resno (\d+) :resname (\S+) :ressortgroupref \d+ :resorigtbl (\d+) :resorigcol (\d+) :resjunk false', 'g') AS m
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = v.oid AND a.attnum = m[1]::int AND a.attname = m[2]
+	JOIN pg_catalog.pg_class t ON t.oid = m[3]::oid
+	JOIN pg_catalog.pg_namespace tn ON tn.oid = t.relnamespace
+	JOIN pg_catalog.pg_attribute ta ON ta.attrelid = t.oid AND ta.attnum = m[4]::int
+	WHERE vn.nspname = $1 AND v.relkind IN ('v', 'm') AND rw.rulename = '_RETURN'
+	AND EXISTS (
+		SELECT 1 FROM pg_catalog.pg_depend d
+		WHERE d.classid = 'pg_catalog.pg_rewrite'::regclass AND d.objid = rw.oid
+		AND d.refclassid = 'pg_catalog.pg_class'::regclass
+		AND d.refobjid = t.oid AND d.refobjsubid = ta.attnum
+	)
+	ORDER BY 1, 2`, schema)
+	if err := report.optional("view column origins", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Subqueries have target lists of their own, so a view column with more
+	// than one candidate is ambiguous and left alone
+	origins := map[string]map[string]*viewColumnOrigin{}
+	ambiguous := map[string]bool{}
+	for rows.Next() {
+		var viewName, columnName string
+		origin := &viewColumnOrigin{}
+		if err := rows.Scan(&viewName, &columnName, &origin.relation, &origin.column, &origin.description); err != nil {
+			return err
+		}
+		if origins[viewName] == nil {
+			origins[viewName] = map[string]*viewColumnOrigin{}
+		}
+		if existing, ok := origins[viewName][columnName]; ok {
+			if existing.relation != origin.relation || existing.column != origin.column {
+				ambiguous[viewName+"."+columnName] = true
+			}
+			continue
+		}
+		origins[viewName][columnName] = origin
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	inherit := func(viewName string, columns []ColumnDefinition) {
+		for idx, col := range columns {
+			if col.Description != "" {
+				continue
+			}
+			relation, column := viewName, col.Name
+			// Bounded, in case of a cycle through views being replaced
+			for depth := 0; depth < 16; depth++ {
+				if ambiguous[relation+"."+column] {
+					break
+				}
+				origin, ok := origins[relation][column]
+				if !ok || columnListed(excludeColumns, origin.relation, origin.column) {
+					break
+				}
+				if origin.description != "" {
+					columns[idx].Description = origin.description
+					columns[idx].InheritedFrom = origin.relation + "." + origin.column
+					break
+				}
+				relation, column = origin.relation, origin.column
+			}
+		}
+	}
+	for _, view := range views {
+		inherit(view.Name, view.Columns)
+	}
+	for _, view := range matviews {
+		inherit(view.Name, view.Columns)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// addViewDependencies fills in the tables, views and materialized views
+// each view selects from, as recorded by the view's rewrite rule. Those
+// outside the schema are schema qualified.
+func addViewDependencies(ctx context.Context, db *sqrlx.Wrapper, schema string, views []View, matviews []MaterializedView, report *Report) error {
+	rows, err := db.QueryRaw(ctx, `SELECT DISTINCT v.relname,
+	CASE WHEN rn.nspname = $1 THEN r.relname::text ELSE rn.nspname || '.' || r.relname END
+	FROM pg_catalog.pg_depend d
+	JOIN pg_catalog.pg_rewrite rw ON rw.oid = d.objid
+	JOIN pg_catalog.pg_class v ON v.oid = rw.ev_class
+	JOIN pg_catalog.pg_namespace vn ON vn.oid = v.relnamespace
+	JOIN pg_catalog.pg_class r ON r.oid = d.refobjid
+	JOIN pg_catalog.pg_namespace rn ON rn.oid = r.relnamespace
+	WHERE d.classid = 'pg_catalog.pg_rewrite'::regclass
+	AND d.refclassid = 'pg_catalog.pg_class'::regclass
+	AND d.deptype = 'n'
+	AND vn.nspname = $1 AND v.relkind IN ('v', 'm')
+	AND r.oid <> v.oid
+	ORDER BY 1, 2`, schema)
+	if err := report.optional("view dependencies", err); err != nil || rows == nil {
+		return err
+	}
+	defer rows.Close()
+
+	byName := map[string]*[]string{}
+	for idx := range views {
+		byName[views[idx].Name] = &views[idx].DependsOn
+	}
+	for idx := range matviews {
+		byName[matviews[idx].Name] = &matviews[idx].DependsOn
+	}
+
+	for rows.Next() {
+		var viewName, dependency string
+		if err := rows.Scan(&viewName, &dependency); err != nil {
+			return err
+		}
+		if dependsOn, ok := byName[viewName]; ok {
+			*dependsOn = append(*dependsOn, dependency)
+		}
+	}
+	return rows.Err()
+}
+
+// viewDependency is a view and what it selects from
+type viewDependency struct {
+	name         string
+	materialized bool
+	dependsOn    []string
+}
+
+func viewDependencies(schema *Schema) []viewDependency {
+	dependencies := []viewDependency{}
+	for _, view := range schema.Views {
+		dependencies = append(dependencies, viewDependency{view.Name, false, view.DependsOn})
+	}
+	for _, view := range schema.MaterializedViews {
+		dependencies = append(dependencies, viewDependency{view.Name, true, view.DependsOn})
+	}
+	return dependencies
+}
+
+// addViewUsage sets each table's UsedBy to the views selecting from it
+func addViewUsage(schema *Schema) {
+	usedBy := map[string][]string{}
+	for _, view := range viewDependencies(schema) {
+		for _, dependency := range view.dependsOn {
+			usedBy[dependency] = append(usedBy[dependency], view.name)
+		}
+	}
+	for idx, table := range schema.Tables {
+		schema.Tables[idx].UsedBy = usedBy[table.Name]
+	}
+}
+
+// viewDependenciesPUMLDump renders which views select from which tables
+// and views as a PlantUML diagram, separate from the entity diagram
+func viewDependenciesPUMLDump(schema *Schema, w io.Writer, options PUMLOptions) error {
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "@startuml")
+	fmt.Fprint(out, options.Header)
+	if len(schema.Schemas) > 1 {
+		fmt.Fprintln(out, "set namespaceSeparator none")
+	}
+
+	dependencies := viewDependencies(schema)
+	isView := map[string]bool{}
+	for _, view := range dependencies {
+		isView[view.name] = true
+		stereotype := "view"
+		if view.materialized {
+			stereotype = "materialized"
+		}
+		fmt.Fprintf(out, "entity %s <<%s>>\n", view.name, stereotype)
+	}
+	// Only the tables something selects from
+	tables := map[string]bool{}
+	for _, view := range dependencies {
+		for _, dependency := range view.dependsOn {
+			if !isView[dependency] && !tables[dependency] {
+				tables[dependency] = true
+				fmt.Fprintf(out, "entity %s\n", dependency)
+			}
+		}
+	}
+	for _, view := range dependencies {
+		for _, dependency := range view.dependsOn {
+			fmt.Fprintf(out, "%s ..> %s\n", view.name, dependency)
+		}
+	}
+
+	fmt.Fprint(out, options.Footer)
+	fmt.Fprintln(out, "@enduml")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// viewDependenciesMermaidDump renders which views select from which tables
+// and views as a Mermaid flowchart, views rounded and tables square
+func viewDependenciesMermaidDump(schema *Schema, w io.Writer) error {
+	out := &strings.Builder{}
+	fmt.Fprintln(out, "flowchart LR")
+
+	dependencies := viewDependencies(schema)
+	isView := map[string]bool{}
+	for _, view := range dependencies {
+		isView[view.name] = true
+		if view.materialized {
+			fmt.Fprintf(out, "  %s[(%s)]\n", view.name, view.name)
+		} else {
+			fmt.Fprintf(out, "  %s(%s)\n", view.name, view.name)
+		}
+	}
+	tables := map[string]bool{}
+	for _, view := range dependencies {
+		for _, dependency := range view.dependsOn {
+			if !isView[dependency] && !tables[dependency] {
+				tables[dependency] = true
+				fmt.Fprintf(out, "  %s[%s]\n", dependency, dependency)
+			}
+		}
+	}
+	for _, view := range dependencies {
+		for _, dependency := range view.dependsOn {
+			fmt.Fprintf(out, "  %s --> %s\n", view.name, dependency)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
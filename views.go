@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+
+	sqrlx "gopkg.daemonl.com/sqrlx"
+)
+
+// View is a plain (not materialized) view
+type View struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Columns     []ColumnDefinition `json:"columns"`
+
+	// Definition is the view's SELECT as postgres reconstructs it
+	Definition string `json:"definition"`
+
+	// DependsOn are the tables and views the view selects from
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// ForeignKeys can only be logical, declared with -logical-keys
+	ForeignKeys []ForeignKeyDefinition `json:"foreignKeys,omitempty"`
+}
+
+// getViews reads the views in the schema with their columns. Descriptions
+// are filled in with the tables'.
+func getViews(ctx context.Context, db *sqrlx.Wrapper, schema string, filter NameFilter) ([]View, error) {
+	rows, err := db.QueryRaw(ctx, `SELECT c.relname, pg_catalog.pg_get_viewdef(c.oid, true)
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind = 'v'
+	ORDER BY c.relname`, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []View{}
+	for rows.Next() {
+		view := View{}
+		if err := rows.Scan(&view.Name, &view.Definition); err != nil {
+			return nil, err
+		}
+		if !filter.Includes(view.Name) {
+			continue
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for idx, view := range views {
+		cols, err := getColumns(ctx, db, schema, view.Name)
+		if err != nil {
+			return nil, err
+		}
+		views[idx].Columns = cols
+	}
+	return views, nil
+}
+
+// View renders a view as an entity with a stereotype to tell it from the
+// tables. Views only have a separator when logical keys declare a key.
+func (c *PUMLWriter) View(view View, stereotype string) {
+	c.Printf("entity %s <<%s>> {\n", view.Name, stereotype)
+	hasKey := false
+	for _, column := range view.Columns {
+		if column.IsKey {
+			c.Column(column, false)
+			hasKey = true
+		}
+	}
+	if hasKey {
+		c.Println("--")
+	}
+	for _, column := range view.Columns {
+		if !column.IsKey {
+			c.Column(column, false)
+		}
+	}
+	c.Println("}")
+
+	if c.IncludeDescriptions && view.Description != "" {
+		c.Printf("note top of %s\n", view.Name)
+		for _, line := range wrapText(view.Description, c.DescriptionWidth) {
+			c.Printf("  %s\n", line)
+		}
+		c.Println("end note")
+	}
+}
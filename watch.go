@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// watchSettle is how long -watch-channel waits for the notifications to stop
+// before reading the schema, as a migration fires one per statement
+const watchSettle = time.Second
+
+// WatchOptions are how -watch notices changes to the schema: reading it
+// every Interval, or when a notification arrives on Channel
+type WatchOptions struct {
+	Interval time.Duration
+	Channel  string
+
+	// PostgresURL is the database to LISTEN to for Channel
+	PostgresURL string
+}
+
+// watchSchema reads the schema, then reads it again every interval or on
+// every notification, calling generate each time it is different. Errors
+// reading the schema or generating the outputs are logged rather than
+// returned, as the database may be restarting or part way through a
+// migration, and the next read will likely do.
+func watchSchema(read func() (*Schema, *Report, error), generate func(*Schema, *Report) error, options WatchOptions) error {
+	if options.Interval <= 0 {
+		return fmt.Errorf("-watch-interval must be positive")
+	}
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	var listener *pq.Listener
+	if options.Channel != "" {
+		listener = pq.NewListener(options.PostgresURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("Watching: %s", err.Error())
+			}
+		})
+		defer listener.Close()
+		if err := listener.Listen(options.Channel); err != nil {
+			return fmt.Errorf("listening on %s: %w", options.Channel, err)
+		}
+	}
+
+	var last []byte
+	for {
+		fullSchema, report, err := read()
+		if err != nil {
+			log.Printf("Watching: %s", err.Error())
+		} else if current, err := watchSnapshot(fullSchema); err != nil {
+			return err
+		} else if !bytes.Equal(current, last) {
+			if err := generate(fullSchema, report); err != nil {
+				// Left unset so the next read tries again
+				log.Printf("Watching: %s", err.Error())
+			} else {
+				if last != nil {
+					log.Printf("Schema changed, regenerated the outputs")
+				}
+				last = current
+			}
+		}
+
+		if listener == nil {
+			<-ticker.C
+			continue
+		}
+		waitForNotification(listener, ticker)
+	}
+}
+
+// watchSnapshot is the schema to compare between reads, without the live
+// data -sizes, -stats, -profile, -maintenance and -sample read, which
+// changes without the schema changing
+func watchSnapshot(schema *Schema) ([]byte, error) {
+	snapshot := *schema
+	withoutStats := func(columns []ColumnDefinition) []ColumnDefinition {
+		columns = append([]ColumnDefinition{}, columns...)
+		for idx := range columns {
+			columns[idx].Stats = nil
+			columns[idx].Profile = nil
+		}
+		return columns
+	}
+	snapshot.Tables = append([]Table{}, schema.Tables...)
+	for idx := range snapshot.Tables {
+		table := &snapshot.Tables[idx]
+		table.Size = nil
+		table.Maintenance = nil
+		table.Sample = nil
+		table.KeyColumns = withoutStats(table.KeyColumns)
+		table.Columns = withoutStats(table.Columns)
+	}
+	snapshot.MaterializedViews = append([]MaterializedView{}, schema.MaterializedViews...)
+	for idx := range snapshot.MaterializedViews {
+		snapshot.MaterializedViews[idx].LastAnalyzed = nil
+	}
+	snapshot.Sequences = append([]Sequence{}, schema.Sequences...)
+	for idx := range snapshot.Sequences {
+		snapshot.Sequences[idx].LastValue = nil
+	}
+	return json.Marshal(snapshot)
+}
+
+// waitForNotification blocks until a notification, or a reconnection which
+// may have missed some, pinging the connection every tick so a dropped one
+// is noticed
+func waitForNotification(listener *pq.Listener, ticker *time.Ticker) {
+	for {
+		select {
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				log.Printf("Watching: %s", err.Error())
+			}
+		case <-listener.Notify:
+			settle := time.NewTimer(watchSettle)
+			for {
+				select {
+				case <-listener.Notify:
+					settle.Reset(watchSettle)
+				case <-settle.C:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// yamlDump writes the same document as jsonDump in YAML
+func yamlDump(schema *Schema, w io.Writer) error {
+	return writeYAML(w, schemaEnvelope{
+		Version: schemaFormatVersion,
+		Schema:  schema,
+	})
+}
+
+// writeYAML writes value as YAML. It goes through the JSON encoding so the
+// keys and their order match what JSON outputs of the same value have.
+func writeYAML(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	ordered, err := jsonToYAML(decoder)
+	if err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(ordered)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// jsonToYAML reads the next value from decoder, keeping object keys in order
+// as a yaml.MapSlice
+func jsonToYAML(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		switch token {
+		case '{':
+			object := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := jsonToYAML(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err := decoder.Token()
+			return object, err
+		case '[':
+			array := []interface{}{}
+			for decoder.More() {
+				value, err := jsonToYAML(decoder)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			}
+			_, err := decoder.Token()
+			return array, err
+		default:
+			return nil, fmt.Errorf("unexpected %s", token)
+		}
+	case json.Number:
+		if i, err := token.Int64(); err == nil {
+			return i, nil
+		}
+		return token.Float64()
+	default:
+		return token, nil
+	}
+}